@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/middleware"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// GetUser - Admin-only endpoint with a user's details and account state
+func GetUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	var sessions, apiTokens int64
+	database.DB.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND last_seen_at > ?", user.ID, time.Now().Add(-services.RefreshTokenTTL)).
+		Count(&sessions)
+	database.DB.Model(&model.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", user.ID, time.Now()).
+		Count(&apiTokens)
+
+	var identities []model.UserIdentity
+	database.DB.Where("user_id = ?", user.ID).Find(&identities)
+	providers := make([]gin.H, 0, len(identities))
+	for _, identity := range identities {
+		providers = append(providers, gin.H{"issuer": identity.Issuer, "email": identity.Email, "linked_at": identity.CreatedAt})
+	}
+
+	var lockedUntil *time.Time
+	if until := services.AccountLockedUntil(user.Email); !until.IsZero() {
+		lockedUntil = &until
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":            model.NewUserResponse(user),
+		"totp_enabled":    services.HasTOTP(user.ID),
+		"has_password":    user.PasswordHash != "!",
+		"active_sessions": sessions,
+		"api_tokens":      apiTokens,
+		"sso_identities":  providers,
+		"locked_until":    lockedUntil,
+	})
+}
+
+// DisableUser - Admin-only endpoint that blocks a user from logging in and signs them out
+func DisableUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	actorID := c.GetUint("user_id")
+	if user.ID == actorID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot disable your own account"})
+		return
+	}
+
+	err := services.DisableUser(user)
+	if errors.Is(err, services.ErrLastAdmin) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot disable the last admin"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable account"})
+		return
+	}
+
+	services.Audit(services.AuditAccountDisabled, &actorID, &user.ID, c.ClientIP(), user.Email)
+	c.JSON(http.StatusOK, gin.H{"message": "Account disabled"})
+}
+
+// EnableUser - Admin-only endpoint that re-enables a disabled account
+func EnableUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.EnableUser(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable account"})
+		return
+	}
+
+	actorID := c.GetUint("user_id")
+	services.Audit(services.AuditAccountEnabled, &actorID, &user.ID, c.ClientIP(), user.Email)
+	c.JSON(http.StatusOK, gin.H{"message": "Account enabled"})
+}
+
+// ForceLogoutUser - Admin-only endpoint that revokes all of a user's sessions
+func ForceLogoutUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.RevokeAllSessions(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	actorID := c.GetUint("user_id")
+	services.Audit(services.AuditSessionsRevoked, &actorID, &user.ID, c.ClientIP(), user.Email)
+	c.JSON(http.StatusOK, gin.H{"message": "User signed out everywhere"})
+}
+
+// ImpersonateUser - Admin-only endpoint returning a short-lived access token
+// to act as a user for support. There's no refresh token, account settings
+// are off limits and every change made with it is audited.
+func ImpersonateUser(c *gin.Context) {
+	var body struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason for impersonating is required"})
+		return
+	}
+
+	target, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	admin := c.MustGet("user").(model.User)
+	if _, impersonating := c.Get("impersonator_id"); impersonating || c.GetString("auth_type") != middleware.AuthTypeSession {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Impersonation must be started from a logged-in admin session"})
+		return
+	}
+	if target.ID == admin.ID || target.Role == model.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admins cannot be impersonated"})
+		return
+	}
+	if target.DisabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Account disabled"})
+		return
+	}
+
+	token, expiresAt, err := services.IssueImpersonationToken(admin, target, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	services.Audit(services.AuditImpersonationStart, &admin.ID, &target.ID, c.ClientIP(),
+		fmt.Sprintf("%s impersonated %s: %s", admin.Email, target.Email, body.Reason))
+
+	// ✅ Returned in the body only, so the admin's own cookies are left alone
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"expires_at": expiresAt,
+		"user":       model.NewUserResponse(target),
+	})
+}
+
+// GetAuditLogs - Admin-only endpoint listing audit entries, newest first.
+// Filters: ?user_id= (actor or target), ?action=, ?before_id= for paging.
+func GetAuditLogs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	limit = min(max(limit, 1), 200)
+
+	query := database.DB.Model(&model.AuditLog{})
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("actor_id = ? OR target_user_id = ?", userID, userID)
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if beforeID := c.Query("before_id"); beforeID != "" {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var entries []model.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list audit logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// findUser loads the user in the :id parameter, writing a 404 if there isn't one
+func findUser(c *gin.Context) (model.User, bool) {
+	var user model.User
+
+	// ✅ Parse first: GORM treats a non-numeric string condition as raw SQL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return user, false
+	}
+	if err := database.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+	return user, true
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// GetAPITokens lists the logged-in user's API tokens (never the token values)
+func GetAPITokens(c *gin.Context) {
+	var tokens []model.APIToken
+	database.DB.Where("user_id = ?", c.GetUint("user_id")).Order("id").Find(&tokens)
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateAPIToken mints a scoped token; the value is only shown in this response
+func CreateAPIToken(c *gin.Context) {
+	var body struct {
+		Name      string     `json:"name" binding:"required"`
+		Scopes    []string   `json:"scopes" binding:"required"`
+		ExpiresAt *time.Time `json:"expires_at"` // Optional, omit for a token that never expires
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	if body.ExpiresAt != nil && body.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	token, raw, err := services.CreateAPIToken(c.GetUint("user_id"), body.Name, body.Scopes, body.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "scopes": model.AllScopes})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Store this token now, it won't be shown again",
+		"token":     raw,
+		"api_token": token,
+	})
+}
+
+// RevokeAPIToken permanently disables a token
+func RevokeAPIToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	result := database.DB.Model(&model.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, c.GetUint("user_id")).
+		Update("revoked_at", time.Now())
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API token not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked"})
+}
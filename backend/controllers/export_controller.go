@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// RequestExport queues a ZIP of all the user's data. The user is notified
+// (in-app and by email) when it's ready.
+func RequestExport(c *gin.Context) {
+	export, err := services.RequestDataExport(c.GetUint("user_id"))
+	if errors.Is(err, services.ErrExportInProgress) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "export": export})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request export"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Export requested, we'll notify you when it's ready",
+		"export":  export,
+	})
+}
+
+// GetExports lists the user's exports, newest first
+func GetExports(c *gin.Context) {
+	var exports []model.DataExport
+	database.DB.Where("user_id = ?", c.GetUint("user_id")).Order("id DESC").Limit(20).Find(&exports)
+	c.JSON(http.StatusOK, exports)
+}
+
+// DownloadExport returns a short-lived download link for a finished export
+func DownloadExport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export ID"})
+		return
+	}
+
+	var export model.DataExport
+	if err := database.DB.Where("id = ? AND user_id = ?", id, c.GetUint("user_id")).First(&export).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		return
+	}
+	if export.Status != "ready" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Export is " + export.Status})
+		return
+	}
+
+	url, err := services.GeneratePreSignedURL(export.FileKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create download link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": url, "expires_at": export.ExpiresAt})
+}
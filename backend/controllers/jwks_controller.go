@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// JWKS publishes the public keys our tokens are signed with, so other
+// services can verify them without sharing a secret
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": services.SigningKeySet()})
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// GetMFAStatus reports whether two-factor authentication is enabled
+func GetMFAStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"totp_enabled": services.HasTOTP(c.GetUint("user_id"))})
+}
+
+// EnrollTOTP starts TOTP setup and returns the secret and otpauth:// URI to render as a QR code
+func EnrollTOTP(c *gin.Context) {
+	user := c.MustGet("user").(model.User)
+
+	secret, uri, err := services.StartTOTPEnrollment(user)
+	if errors.Is(err, services.ErrMFAAlreadyEnabled) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": uri,
+		"message":          "Scan the URI with your authenticator app, then confirm with a code",
+	})
+}
+
+// ConfirmTOTP enables TOTP after checking a code from the app and returns one-time recovery codes
+func ConfirmTOTP(c *gin.Context) {
+	var body struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	codes, err := services.ConfirmTOTPEnrollment(c.GetUint("user_id"), body.Code)
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrMFANotEnrolled), errors.Is(err, services.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled. Store these recovery codes now, they won't be shown again",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTOTP turns off two-factor authentication (requires a current code or recovery code)
+func DisableTOTP(c *gin.Context) {
+	userID, ok := verifyMFACode(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DisableTOTP(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes (requires a current code or recovery code)
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := verifyMFACode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := services.RegenerateRecoveryCodes(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// verifyMFACode checks the {"code"} in the body, writing the error response if it's wrong
+func verifyMFACode(c *gin.Context) (uint, bool) {
+	var body struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return 0, false
+	}
+
+	userID := c.GetUint("user_id")
+	if err := services.VerifySecondFactor(userID, body.Code); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return 0, false
+	}
+	return userID, true
+}
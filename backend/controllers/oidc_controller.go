@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// Cookie holding the signed state/nonce/PKCE verifier between login and callback
+const oidcFlowCookie = "oidc_flow"
+
+// OIDCLogin redirects the browser to the OpenID Connect provider
+func OIDCLogin(c *gin.Context) {
+	cfg, err := services.LoadOIDCConfig()
+	if errors.Is(err, services.ErrOIDCNotConfigured) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	authURL, flow, err := services.OIDCAuthURL(cfg)
+	if err != nil {
+		log.Println("❌ OIDC login failed:", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		return
+	}
+
+	c.SetCookie(oidcFlowCookie, flow, 600, "/auth/oidc", "", false, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback finishes SSO: it verifies the provider's response, maps the
+// identity to a user and, after the same checks a password login runs,
+// starts a session or hands over to the second factor step.
+func OIDCCallback(c *gin.Context) {
+	// Whatever happens, the flow cookie is single-use
+	signedFlow, _ := c.Cookie(oidcFlowCookie)
+	c.SetCookie(oidcFlowCookie, "", -1, "/auth/oidc", "", false, true)
+
+	cfg, err := services.LoadOIDCConfig()
+	if errors.Is(err, services.ErrOIDCNotConfigured) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		oidcFailed(c, "provider returned "+providerErr)
+		return
+	}
+
+	flow, err := services.ParseOIDCFlow(signedFlow)
+	if err != nil || c.Query("state") != flow.State {
+		oidcFailed(c, "state mismatch")
+		return
+	}
+
+	identity, err := services.ExchangeOIDCCode(cfg, flow, c.Query("code"))
+	if err != nil {
+		oidcFailed(c, err.Error())
+		return
+	}
+
+	user, err := services.UserForOIDCIdentity(identity)
+	if err != nil {
+		oidcFailed(c, err.Error())
+		return
+	}
+
+	// ✅ The same gates as a password login: disabled accounts, email verification, second factor
+	mfaToken, err := checkLoginGates(user)
+	if err != nil {
+		oidcFailed(c, err.Error())
+		return
+	}
+	if mfaToken != "" {
+		// In the fragment, so it never reaches server logs or Referer headers.
+		// The frontend finishes the login through POST /login/mfa.
+		c.Redirect(http.StatusFound, services.FrontendURL+"/auth/login#mfa_token="+url.QueryEscape(mfaToken))
+		return
+	}
+
+	if _, err := startSession(c, user); err != nil {
+		oidcFailed(c, err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusFound, services.FrontendURL+"/dashboard")
+}
+
+// oidcFailed logs why SSO failed and sends the browser back to the login page
+func oidcFailed(c *gin.Context, reason string) {
+	log.Println("❌ OIDC callback failed:", reason)
+	c.Redirect(http.StatusFound, services.FrontendURL+"/auth/login?error=sso_failed")
+}
@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+const testOIDCClientID = "task-manager"
+
+// stubIdP is an OpenID Connect provider serving discovery, JWKS and token
+// endpoints. idToken decides the claims of the ID token it hands out.
+type stubIdP struct {
+	*httptest.Server
+	key ed25519.PrivateKey
+
+	mu           sync.Mutex
+	codeVerifier string // From the last token request
+	idToken      func(idp *stubIdP, nonce string) jwt.MapClaims
+	nonces       map[string]string // Authorization code -> nonce
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &stubIdP{key: key, nonces: map[string]string{}}
+	idp.idToken = func(idp *stubIdP, nonce string) jwt.MapClaims {
+		return idp.claims("subject-1", "frank@example.com", true, nonce)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []services.JWK{{
+			Kty: "OKP", Kid: "stub", Use: "sig", Alg: "EdDSA", Crv: "Ed25519",
+			X: base64.RawURLEncoding.EncodeToString(idp.key.Public().(ed25519.PublicKey)),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+
+		idp.codeVerifier = r.PostForm.Get("code_verifier")
+		nonce, ok := idp.nonces[r.PostForm.Get("code")]
+		if !ok || r.PostForm.Get("client_id") != testOIDCClientID {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, idp.idToken(idp, nonce))
+		token.Header["kid"] = "stub"
+		signed, _ := token.SignedString(idp.key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+
+	t.Setenv("OIDC_ISSUER", idp.URL)
+	t.Setenv("OIDC_CLIENT_ID", testOIDCClientID)
+	return idp
+}
+
+// claims are the claims of a valid ID token
+func (idp *stubIdP) claims(subject, email string, verified bool, nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            idp.URL,
+		"aud":            testOIDCClientID,
+		"sub":            subject,
+		"email":          email,
+		"email_verified": verified,
+		"nonce":          nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	}
+}
+
+// oidcRouter wires the SSO routes the way main.go does
+func oidcRouter() *gin.Engine {
+	r := gin.New()
+	r.GET("/auth/oidc/login", OIDCLogin)
+	r.GET("/auth/oidc/callback", OIDCCallback)
+	return r
+}
+
+// oidcLogin starts a login and returns the flow cookie plus the query sent to the provider
+func oidcLogin(t *testing.T, r *gin.Engine) (*http.Cookie, url.Values) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want %d (body %s)", w.Code, http.StatusFound, w.Body.String())
+	}
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == oidcFlowCookie {
+			return cookie, location.Query()
+		}
+	}
+	t.Fatal("login did not set the flow cookie")
+	return nil, nil
+}
+
+// oidcCallback plays the provider redirecting back with code and state
+func oidcCallback(r *gin.Engine, flow *http.Cookie, code, state string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?"+url.Values{"code": {code}, "state": {state}}.Encode(), nil)
+	if flow != nil {
+		req.AddCookie(flow)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// sessionStarted reports whether the response logged the browser in
+func sessionStarted(w *httptest.ResponseRecorder) bool {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "Authorization" && cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOIDCCallbackSignsIn(t *testing.T) {
+	setupTestDB(t)
+	setupSigningKey(t)
+	idp := newStubIdP(t)
+	r := oidcRouter()
+
+	flow, query := oidcLogin(t, r)
+	idp.nonces["code-1"] = query.Get("nonce")
+
+	w := oidcCallback(r, flow, "code-1", query.Get("state"))
+	if w.Code != http.StatusFound || !strings.HasSuffix(w.Header().Get("Location"), "/dashboard") {
+		t.Fatalf("callback = %d to %q, want a redirect to the dashboard", w.Code, w.Header().Get("Location"))
+	}
+	if !sessionStarted(w) {
+		t.Fatal("callback did not start a session")
+	}
+
+	// ✅ PKCE: the verifier sent to the token endpoint matches the challenge sent to the provider
+	sum := sha256.Sum256([]byte(idp.codeVerifier))
+	if query.Get("code_challenge_method") != "S256" || base64.RawURLEncoding.EncodeToString(sum[:]) != query.Get("code_challenge") {
+		t.Fatalf("code_verifier %q does not match code_challenge %q", idp.codeVerifier, query.Get("code_challenge"))
+	}
+}
+
+func TestOIDCCallbackRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   func(state string) string
+		noFlow  bool
+		idToken func(idp *stubIdP, nonce string) jwt.MapClaims
+	}{
+		{name: "state mismatch", state: func(string) string { return "forged" }},
+		{name: "missing flow cookie", noFlow: true},
+		{name: "nonce mismatch", idToken: func(idp *stubIdP, nonce string) jwt.MapClaims {
+			return idp.claims("subject-1", "frank@example.com", true, "replayed")
+		}},
+		{name: "wrong audience", idToken: func(idp *stubIdP, nonce string) jwt.MapClaims {
+			claims := idp.claims("subject-1", "frank@example.com", true, nonce)
+			claims["aud"] = "another-client"
+			return claims
+		}},
+		{name: "wrong issuer", idToken: func(idp *stubIdP, nonce string) jwt.MapClaims {
+			claims := idp.claims("subject-1", "frank@example.com", true, nonce)
+			claims["iss"] = "https://evil.example.com"
+			return claims
+		}},
+		{name: "expired ID token", idToken: func(idp *stubIdP, nonce string) jwt.MapClaims {
+			claims := idp.claims("subject-1", "frank@example.com", true, nonce)
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			return claims
+		}},
+		{name: "unverified email", idToken: func(idp *stubIdP, nonce string) jwt.MapClaims {
+			return idp.claims("subject-1", "frank@example.com", false, nonce)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			setupSigningKey(t)
+			idp := newStubIdP(t)
+			if tt.idToken != nil {
+				idp.idToken = tt.idToken
+			}
+			r := oidcRouter()
+
+			flow, query := oidcLogin(t, r)
+			idp.nonces["code-1"] = query.Get("nonce")
+			state := query.Get("state")
+			if tt.state != nil {
+				state = tt.state(state)
+			}
+			if tt.noFlow {
+				flow = nil
+			}
+
+			w := oidcCallback(r, flow, "code-1", state)
+			if w.Code != http.StatusFound || !strings.Contains(w.Header().Get("Location"), "error=sso_failed") {
+				t.Fatalf("callback = %d to %q, want a redirect with error=sso_failed", w.Code, w.Header().Get("Location"))
+			}
+			if sessionStarted(w) {
+				t.Fatal("a rejected callback started a session")
+			}
+
+			var users int64
+			database.DB.Model(&model.User{}).Count(&users)
+			if users != 0 {
+				t.Fatalf("%d users were created", users)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackRunsLoginGates(t *testing.T) {
+	setupTestDB(t)
+	setupSigningKey(t)
+	idp := newStubIdP(t)
+	r := oidcRouter()
+
+	// ✅ SSO doesn't get around the second factor
+	user, _ := mfaUser(t, "frank")
+	database.DB.Create(&model.UserIdentity{UserID: user.ID, Issuer: idp.URL, Subject: "subject-1", Email: user.Email})
+
+	flow, query := oidcLogin(t, r)
+	idp.nonces["code-1"] = query.Get("nonce")
+	w := oidcCallback(r, flow, "code-1", query.Get("state"))
+	if !strings.Contains(w.Header().Get("Location"), "#mfa_token=") {
+		t.Fatalf("callback redirected to %q, want the MFA step", w.Header().Get("Location"))
+	}
+	if sessionStarted(w) {
+		t.Fatal("callback started a session before the second factor")
+	}
+
+	// ✅ Nor does it let disabled accounts in
+	now := time.Now()
+	database.DB.Where("user_id = ?", user.ID).Delete(&model.TOTPCredential{})
+	database.DB.Model(&user).Update("disabled_at", now)
+
+	flow, query = oidcLogin(t, r)
+	idp.nonces["code-2"] = query.Get("nonce")
+	w = oidcCallback(r, flow, "code-2", query.Get("state"))
+	if !strings.Contains(w.Header().Get("Location"), "error=sso_failed") || sessionStarted(w) {
+		t.Fatalf("disabled account callback redirected to %q", w.Header().Get("Location"))
+	}
+}
+
+func TestUserForOIDCIdentity(t *testing.T) {
+	const issuer = "https://idp.example.com"
+
+	t.Run("creates a user for a new verified identity", func(t *testing.T) {
+		setupTestDB(t)
+		user, err := services.UserForOIDCIdentity(services.OIDCIdentity{Issuer: issuer, Subject: "s1", Email: "gina@example.com", EmailVerified: true, Username: "gina"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.Username != "gina" || user.Role != model.RoleUser || user.EmailVerifiedAt == nil {
+			t.Fatalf("created %+v", user)
+		}
+	})
+
+	t.Run("links a verified identity to the account with that email", func(t *testing.T) {
+		setupTestDB(t)
+		existing := createTestUser(t, "gina", model.RoleUser)
+		user, err := services.UserForOIDCIdentity(services.OIDCIdentity{Issuer: issuer, Subject: "s1", Email: existing.Email, EmailVerified: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != existing.ID {
+			t.Fatalf("got user %d, want the existing user %d", user.ID, existing.ID)
+		}
+	})
+
+	t.Run("refuses unverified emails", func(t *testing.T) {
+		setupTestDB(t)
+		existing := createTestUser(t, "gina", model.RoleUser)
+		for _, email := range []string{existing.Email, "someone-new@example.com"} {
+			if _, err := services.UserForOIDCIdentity(services.OIDCIdentity{Issuer: issuer, Subject: "s1", Email: email}); err == nil {
+				t.Fatalf("accepted unverified %s", email)
+			}
+		}
+		var links int64
+		database.DB.Model(&model.UserIdentity{}).Count(&links)
+		if links != 0 {
+			t.Fatalf("%d identities were linked", links)
+		}
+	})
+
+	t.Run("refuses identities without an email", func(t *testing.T) {
+		setupTestDB(t)
+		if _, err := services.UserForOIDCIdentity(services.OIDCIdentity{Issuer: issuer, Subject: "s1", EmailVerified: true}); err == nil {
+			t.Fatal("accepted an identity without an email")
+		}
+	})
+
+	t.Run("a linked identity keeps its user", func(t *testing.T) {
+		setupTestDB(t)
+		first, err := services.UserForOIDCIdentity(services.OIDCIdentity{Issuer: issuer, Subject: "s1", Email: "gina@example.com", EmailVerified: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Later logins match on issuer and subject, even if the email changed at the provider
+		again, err := services.UserForOIDCIdentity(services.OIDCIdentity{Issuer: issuer, Subject: "s1", Email: "gina@new.example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.ID != first.ID {
+			t.Fatalf("got user %d, want %d", again.ID, first.ID)
+		}
+
+		// The same subject at another issuer is someone else
+		other, err := services.UserForOIDCIdentity(services.OIDCIdentity{Issuer: "https://other.example.com", Subject: "s1", Email: "hank@example.com", EmailVerified: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other.ID == first.ID {
+			t.Fatal("an identity from another issuer signed in as the same user")
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// VerifyEmail confirms the address a verification token was sent to
+func VerifyEmail(c *gin.Context) {
+	var body struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	token, err := services.ConsumeUserToken(body.Token, model.TokenPurposeVerifyEmail)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+
+	// ✅ Only verify if the address hasn't changed since the link was sent
+	result := database.DB.Model(&model.User{}).
+		Where("id = ? AND email = ?", token.UserID, token.Email).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerification emails a fresh verification link. The response is the
+// same whether or not the address exists, so it can't be used to probe accounts.
+func ResendVerification(c *gin.Context) {
+	var body struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, "email = ?", body.Email).Error; err == nil && user.EmailVerifiedAt == nil {
+		if err := services.SendVerificationEmail(user); err != nil {
+			log.Println("❌ Failed to queue verification email:", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists and is unverified, a new link is on its way"})
+}
+
+// ForgotPassword emails a password reset link. The response is the same
+// whether or not the address exists, so it can't be used to probe accounts.
+func ForgotPassword(c *gin.Context) {
+	var body struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, "email = ?", body.Email).Error; err == nil {
+		if err := services.SendPasswordResetEmail(user); err != nil {
+			log.Println("❌ Failed to queue password reset email:", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a reset link is on its way"})
+}
+
+// ResetPassword sets a new password using a reset token and signs out every session
+func ResetPassword(c *gin.Context) {
+	var body struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	// ✅ Check the policy before using up the token, so a rejected password can be
+	// retried, and against the account's own username and email like signup does
+	pending, err := services.LookupUserToken(body.Token, model.TokenPurposePasswordReset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset link"})
+		return
+	}
+	var user model.User
+	if err := database.DB.First(&user, pending.UserID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset link"})
+		return
+	}
+	if err := services.ValidatePassword(body.Password, user.Username, user.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := services.ConsumeUserToken(body.Token, model.TokenPurposePasswordReset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset link"})
+		return
+	}
+
+	hash, err := services.HashPassword(body.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	// ✅ Only while the account still has the address the link was sent to
+	result := database.DB.Model(&model.User{}).
+		Where("id = ? AND email = ?", token.UserID, token.Email).
+		Update("password_hash", hash)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset link"})
+		return
+	}
+
+	// ✅ Receiving the reset email also proves the address belongs to the user
+	database.DB.Model(&model.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", token.UserID, token.Email).
+		Update("email_verified_at", time.Now())
+
+	if err := services.RevokeAllSessions(token.UserID); err != nil {
+		log.Println("❌ Failed to revoke sessions after password reset:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in"})
+}
+
+// ChangePassword sets a new password for the logged-in user, who must supply
+// the current one. Every other session is signed out.
+func ChangePassword(c *gin.Context) {
+	var body struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	user := c.MustGet("user").(model.User)
+
+	// ✅ Guessing the current password here counts towards the login lockout
+	if !loginAllowed(c, user.Email) {
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(body.CurrentPassword)); err != nil {
+		loginFailed(c, user.Email, user.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	if body.NewPassword == body.CurrentPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New password must be different from the current one"})
+		return
+	}
+	if err := services.ValidatePassword(body.NewPassword, user.Username, user.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hash, err := services.HashPassword(body.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	if err := database.DB.Model(&user).Update("password_hash", hash).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	if err := services.RevokeOtherSessions(user.ID, c.GetString("session_id")); err != nil {
+		log.Println("❌ Failed to revoke sessions after password change:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed, other sessions have been signed out"})
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/middleware"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// passwordRouter wires the profile and password reset routes the way main.go does
+func passwordRouter() *gin.Engine {
+	r := gin.New()
+	r.POST("/password/reset", ResetPassword)
+	r.PATCH("/me", middleware.RequireAuth, middleware.RequireSession, UpdateMe)
+	return r
+}
+
+func TestEmailChangeRevokesResetLinks(t *testing.T) {
+	setupTestDB(t)
+	setupSigningKey(t)
+
+	user := createTestUser(t, "dave", model.RoleUser)
+	hash, err := services.HashPassword("old-Password-for-dave-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.DB.Model(&user).Update("password_hash", hash)
+
+	// ✅ A reset link that was sent to the old address before the change
+	raw, err := services.IssueUserToken(user.ID, model.TokenPurposePasswordReset, user.Email, services.PasswordResetTokenTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := passwordRouter()
+	w := doJSON(r, http.MethodPatch, "/me", accessTokenFor(t, user), map[string]string{
+		"email":            "dave@new.example.com",
+		"current_password": "old-Password-for-dave-1",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("email change = %d %s, want 200", w.Code, w.Body)
+	}
+
+	w = doJSON(r, http.MethodPost, "/password/reset", "", map[string]string{
+		"token":    raw,
+		"password": "correct-Horse-battery-staple-42",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("old reset link after the email change = %d %s, want 400", w.Code, w.Body)
+	}
+	database.DB.First(&user, user.ID)
+	if user.PasswordHash != hash {
+		t.Fatal("the old reset link changed the password")
+	}
+
+	var notice model.OutboxEmail
+	if err := database.DB.Where(`"to" = ? AND subject = ?`, "dave@example.com", "Your email address was changed").First(&notice).Error; err != nil {
+		t.Fatal("the old address wasn't told about the change:", err)
+	}
+}
+
+func TestResetLinkChecksTheAddressItWasSentTo(t *testing.T) {
+	setupTestDB(t)
+
+	user := createTestUser(t, "erin", model.RoleUser)
+	raw, err := services.IssueUserToken(user.ID, model.TokenPurposePasswordReset, user.Email, services.PasswordResetTokenTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The address changed without going through UpdateMe (e.g. by an admin)
+	database.DB.Model(&user).Update("email", "erin@new.example.com")
+
+	w := doJSON(passwordRouter(), http.MethodPost, "/password/reset", "", map[string]string{
+		"token":    raw,
+		"password": "correct-Horse-battery-staple-42",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("reset = %d %s, want 400", w.Code, w.Body)
+	}
+}
+
+func TestResetPasswordChecksPersonalInfo(t *testing.T) {
+	setupTestDB(t)
+
+	user := createTestUser(t, "frankie", model.RoleUser)
+	raw, err := services.IssueUserToken(user.ID, model.TokenPurposePasswordReset, user.Email, services.PasswordResetTokenTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := passwordRouter()
+	w := doJSON(r, http.MethodPost, "/password/reset", "", map[string]string{
+		"token":    raw,
+		"password": "Frankie-battery-staple-42",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("reset to a password with the username = %d %s, want 400", w.Code, w.Body)
+	}
+
+	// ✅ The rejected password didn't use up the link
+	w = doJSON(r, http.MethodPost, "/password/reset", "", map[string]string{
+		"token":    raw,
+		"password": "correct-Horse-battery-staple-42",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("retry with a good password = %d %s, want 200", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const maxAvatarSize = 2 << 20 // 2 MB
+
+// Image types accepted as avatars, by sniffed content type
+var avatarExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// GetMe returns the logged-in user's profile
+func GetMe(c *gin.Context) {
+	c.JSON(http.StatusOK, profileResponse(c.MustGet("user").(model.User)))
+}
+
+// UpdateMe changes the username, email, time zone or locale. Changing the
+// email needs the current password and sends a new verification link.
+func UpdateMe(c *gin.Context) {
+	var body struct {
+		Username        *string `json:"username"`
+		Email           *string `json:"email"`
+		Timezone        *string `json:"timezone"`
+		Locale          *string `json:"locale"`
+		CurrentPassword string  `json:"current_password"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	user := c.MustGet("user").(model.User)
+	updates := map[string]any{}
+
+	if body.Username != nil && *body.Username != user.Username {
+		username := strings.TrimSpace(*body.Username)
+		if username == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Username cannot be empty"})
+			return
+		}
+		updates["username"] = username
+	}
+
+	emailChanged := body.Email != nil && !strings.EqualFold(strings.TrimSpace(*body.Email), user.Email)
+	if emailChanged {
+		email := strings.TrimSpace(*body.Email)
+		if !strings.Contains(email, "@") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+			return
+		}
+
+		// ✅ A stolen session alone shouldn't be enough to take over the account
+		if !loginAllowed(c, user.Email) {
+			return
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(body.CurrentPassword)); err != nil {
+			loginFailed(c, user.Email, user.ID)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is required to change your email"})
+			return
+		}
+
+		updates["email"] = email
+		updates["email_verified_at"] = nil
+	}
+
+	if body.Timezone != nil {
+		if err := services.ValidateTimezone(*body.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["timezone"] = *body.Timezone
+	}
+
+	if body.Locale != nil {
+		if err := services.ValidateLocale(*body.Locale); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["locale"] = *body.Locale
+	}
+
+	oldEmail := user.Email
+	if len(updates) > 0 {
+		if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username or email already exists"})
+			return
+		}
+	}
+	database.DB.First(&user, user.ID)
+
+	if emailChanged {
+		// ✅ Reset and verification links sent to the old address must stop working
+		if err := services.RevokeUserTokens(user.ID); err != nil {
+			log.Println("❌ Failed to revoke email tokens:", err)
+		}
+		if err := services.SendEmailChangedNotice(user, oldEmail); err != nil {
+			log.Println("❌ Failed to queue email change notice:", err)
+		}
+		if err := services.SendVerificationEmail(user); err != nil {
+			log.Println("❌ Failed to queue verification email:", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, profileResponse(user))
+}
+
+// UploadAvatar replaces the profile picture with the "avatar" file of a multipart form
+func UploadAvatar(c *gin.Context) {
+	user := c.MustGet("user").(model.User)
+
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No avatar file provided"})
+		return
+	}
+	if header.Size > maxAvatarSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Avatar must be 2 MB or smaller"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		return
+	}
+	defer file.Close()
+
+	// ✅ Trust the bytes, not the client's Content-Type
+	sniff := make([]byte, 512)
+	n, _ := file.Read(sniff)
+	contentType := http.DetectContentType(sniff[:n])
+	ext, ok := avatarExtensions[contentType]
+	if !ok {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Avatar must be a PNG, JPEG, GIF or WebP image"})
+		return
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+
+	// A new key each time, so cached pre-signed URLs of the old picture stop matching
+	key := fmt.Sprintf("%savatar-%d%s", services.UserFilesPrefix(user.ID), time.Now().UnixNano(), ext)
+	if err := services.UploadToS3(file, contentType, key); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "S3 upload failed"})
+		return
+	}
+
+	previous := user.AvatarKey
+	if err := database.DB.Model(&user).Update("avatar_key", key).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
+		return
+	}
+
+	if previous != "" && previous != key {
+		if err := services.DeleteFromS3(previous); err != nil {
+			log.Println("❌ Failed to delete old avatar:", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, profileResponse(user))
+}
+
+// DeleteMe deletes the account: tasks, attachments and everything else the
+// user owns are removed and the profile is anonymized. Accounts with a
+// password must confirm with it.
+func DeleteMe(c *gin.Context) {
+	var body struct {
+		Password string `json:"password"`
+	}
+	_ = c.ShouldBindJSON(&body)
+
+	user := c.MustGet("user").(model.User)
+
+	// SSO-only accounts have no password to confirm with (see createOIDCUser)
+	if user.PasswordHash != "!" {
+		if !loginAllowed(c, user.Email) {
+			return
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(body.Password)); err != nil {
+			loginFailed(c, user.Email, user.ID)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is required to delete your account"})
+			return
+		}
+	}
+
+	err := services.DeleteAccount(user)
+	if errors.Is(err, services.ErrLastAdmin) {
+		c.JSON(http.StatusConflict, gin.H{"error": "You are the last admin, promote someone else first"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted"})
+}
+
+// profileResponse is the user's own view of their account
+func profileResponse(user model.User) gin.H {
+	var avatarURL string
+	if user.AvatarKey != "" {
+		if url, err := services.GeneratePreSignedURL(user.AvatarKey); err == nil {
+			avatarURL = url
+		}
+	}
+
+	return gin.H{
+		"id":             user.ID,
+		"username":       user.Username,
+		"email":          user.Email,
+		"email_verified": user.EmailVerifiedAt != nil,
+		"role":           user.Role,
+		"timezone":       user.Timezone,
+		"locale":         user.Locale,
+		"avatar_url":     avatarURL,
+		"created_at":     user.CreatedAt,
+	}
+}
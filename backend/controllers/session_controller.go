@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// GetSessions lists the logged-in user's active sessions, marking the current one
+func GetSessions(c *gin.Context) {
+	var sessions []model.Session
+	database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND last_seen_at > ?", c.GetUint("user_id"), time.Now().Add(-services.RefreshTokenTTL)).
+		Order("last_seen_at DESC").
+		Find(&sessions)
+
+	current := c.GetString("session_id")
+	result := make([]gin.H, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, gin.H{
+			"id":           s.ID,
+			"user_agent":   s.UserAgent,
+			"ip":           s.IP,
+			"created_at":   s.CreatedAt,
+			"last_seen_at": s.LastSeenAt,
+			"current":      s.ID == current,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RevokeSession signs out a single session (e.g. a lost laptop)
+func RevokeSession(c *gin.Context) {
+	var session model.Session
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), c.GetUint("user_id")).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := services.RevokeSession(session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	if session.ID == c.GetString("session_id") {
+		clearAuthCookies(c)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeAllSessions signs the user out everywhere, including this session
+func RevokeAllSessions(c *gin.Context) {
+	if err := services.RevokeAllSessions(c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Signed out everywhere"})
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("BCRYPT_COST", "4") // The minimum, tests don't need slow hashes
+	os.Exit(m.Run())
+}
+
+// setupTestDB points database.DB at a fresh in-memory SQLite database
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal("open test database:", err)
+	}
+	err = db.AutoMigrate(
+		&model.User{}, &model.OutboxEmail{},
+		&model.Session{}, &model.RefreshToken{},
+		&model.UserToken{}, &model.TOTPCredential{}, &model.RecoveryCode{}, &model.MFAChallenge{},
+		&model.UserIdentity{},
+		&model.LoginAttempt{}, &model.AuditLog{},
+	)
+	if err != nil {
+		t.Fatal("migrate test database:", err)
+	}
+
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	database.DB = db
+	services.LoginAttempts = services.NewMemoryAttemptStore()
+}
+
+// setupSigningKey loads a throwaway Ed25519 key for signing test tokens
+func setupSigningKey(t *testing.T) {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("JWT_SIGNING_KEY_FILE", path)
+	services.InitSigningKeys()
+}
+
+// createTestUser inserts a verified user with the given role
+func createTestUser(t *testing.T, username, role string) model.User {
+	t.Helper()
+
+	user := model.User{Username: username, Email: username + "@example.com", PasswordHash: "x", Role: role}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatal("create user:", err)
+	}
+	return user
+}
+
+// accessTokenFor logs the user in and returns their access token
+func accessTokenFor(t *testing.T, user model.User) string {
+	t.Helper()
+
+	pair, err := services.IssueTokenPair(user, "go-test", "127.0.0.1")
+	if err != nil {
+		t.Fatal("issue tokens:", err)
+	}
+	return pair.AccessToken
+}
+
+// doJSON sends a JSON request, with a bearer token when one is given
+func doJSON(r http.Handler, method, path, token string, body any) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
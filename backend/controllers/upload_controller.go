@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"path/filepath"
 
@@ -15,12 +16,12 @@ import (
 )
 
 func UploadFiles(c *gin.Context) {
-	userID := c.GetString("userID")
+	userID := c.GetUint("user_id")
 	taskID := c.Param("id")
 
 	// Query the task and check ownership
 	var task model.Task
-	if err := database.DB.First(&task, "id = ? AND user_id = ?", taskID, userID).Error; err != nil {
+	if err := database.DB.First(&task, "task_id = ? AND user_id = ?", taskID, userID).Error; err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You do not own this task"})
 		return
 	}
@@ -48,7 +49,7 @@ func UploadFiles(c *gin.Context) {
 		defer f.Close()
 
 		// Construct S3 key (file path inside bucket)
-		key := fmt.Sprintf("tasks/%s/%s", taskID, filepath.Base(file.Filename))
+		key := services.TaskAttachmentPrefix(task.UserID, task.TaskID) + filepath.Base(file.Filename)
 
 		// Upload to S3 using services.S3Client
 		err = services.UploadToS3(f, file.Header.Get("Content-Type"), key)
@@ -61,6 +62,17 @@ func UploadFiles(c *gin.Context) {
 		urls = append(urls, s3Url)
 	}
 
+	// Let watchers know new files were attached
+	message := fmt.Sprintf("%d file(s) attached to task %q", len(urls), task.Title)
+	if err := services.NotifyWatchers(task.UserID, task.TaskID, userID, services.EventAttachmentUpload, message); err != nil {
+		log.Println("❌ Failed to notify watchers:", err)
+	}
+
+	services.EmitEvent(task.UserID, services.WebhookAttachmentUploaded, gin.H{
+		"task_id": task.TaskID,
+		"files":   urls,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Files uploaded successfully",
 		"files":   urls,
@@ -71,15 +83,15 @@ func ListAttachments(c *gin.Context) {
 	taskID := c.Param("id")
 
 	// Optional: Check task ownership here like we did for uploads
-	userID := c.GetString("userID")
+	userID := c.GetUint("user_id")
 	var task model.Task
-	if err := database.DB.First(&task, "id = ? AND user_id = ?", taskID, userID).Error; err != nil {
+	if err := database.DB.First(&task, "task_id = ? AND user_id = ?", taskID, userID).Error; err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You do not own this task"})
 		return
 	}
 
 	// List all files under this task folder in S3
-	prefix := fmt.Sprintf("tasks/%s/", taskID)
+	prefix := services.TaskAttachmentPrefix(task.UserID, task.TaskID)
 	input := &s3.ListObjectsV2Input{
 		Bucket: aws.String(services.BucketName),
 		Prefix: aws.String(prefix),
@@ -1,15 +1,17 @@
 package controllers
 
 import (
-	"fmt"
+	"errors"
+	"log"
+	"math"
 	"net/http"
-	"os"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/tarun05rawat/go-task-management/database"
 	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -19,7 +21,6 @@ func Signup(c *gin.Context) {
 		Username string `json:"username"`
 		Email    string `json:"email"`
 		Password string `json:"password"`
-		Role     string `json:"role"` // ✅ Allow role input (optional, defaults to "user")
 	}
 
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -27,24 +28,25 @@ func Signup(c *gin.Context) {
 		return
 	}
 
+	// Enforce the password policy
+	if err := services.ValidatePassword(body.Password, body.Username, body.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Hash the password
-	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	hash, err := services.HashPassword(body.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
 	}
 
-	// Default role to "user" if not provided
-	if body.Role == "" {
-		body.Role = "user"
-	}
-
 	// Create user
 	user := model.User{
 		Username:     body.Username,
 		Email:        body.Email,
-		PasswordHash: string(hash),
-		Role:         body.Role, // ✅ Assign role
+		PasswordHash: hash,
+		Role:         model.RoleUser, // ✅ Roles are only ever granted by an admin
 	}
 	result := database.DB.Create(&user)
 
@@ -53,8 +55,13 @@ func Signup(c *gin.Context) {
 		return
 	}
 
+	// Ask the user to confirm their email address
+	if err := services.SendVerificationEmail(user); err != nil {
+		log.Println("❌ Failed to queue verification email:", err)
+	}
+
 	// Return success response
-	c.JSON(http.StatusCreated, gin.H{"message": "User created successfully"})
+	c.JSON(http.StatusCreated, gin.H{"message": "User created successfully, check your email to verify your address"})
 }
 
 // Login function (sets JWT token in an HTTP-only cookie)
@@ -69,48 +76,186 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// Refuse locked out accounts/IPs before checking the password
+	if !loginAllowed(c, body.Email) {
+		return
+	}
+
 	// Look up user by email
 	var user model.User
 	if err := database.DB.First(&user, "email = ?", body.Email).Error; err != nil {
+		loginFailed(c, body.Email, 0)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email/password"})
 		return
 	}
 
 	// Compare password hash
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(body.Password)); err != nil {
+		loginFailed(c, body.Email, user.ID)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email/password"})
 		return
 	}
 
-	// Get JWT secret key (fallback if not set)
-	secret := os.Getenv("SECRET")
-	if secret == "" {
-		secret = "your-fallback-secret-key"
+	// Upgrade the hash if the bcrypt cost has been raised since it was made
+	services.RehashPasswordIfNeeded(user, body.Password)
+
+	mfaToken, err := checkLoginGates(user)
+	switch {
+	case errors.Is(err, services.ErrAccountDisabled):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+		return
+	case errors.Is(err, services.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Email address not verified"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
 	}
 
-	// Generate a JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"role":    user.Role,                                  // ✅ Include role in token
-		"exp":     time.Now().Add(time.Hour * 24 * 30).Unix(), // Expires in 30 days
-	})
+	// Require the second factor before issuing a session
+	if mfaToken != "" {
+		c.JSON(http.StatusOK, gin.H{
+			"message":      "Two-factor authentication required",
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+		})
+		return
+	}
 
-	// Sign and get the complete encoded token as a string
-	tokenString, err := token.SignedString([]byte(secret))
+	completeLogin(c, user)
+}
+
+// LoginMFA function (second login step: exchanges an MFA challenge token and a TOTP or recovery code for a session)
+func LoginMFA(c *gin.Context) {
+	var body struct {
+		MFAToken string `json:"mfa_token" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	challenge, err := services.ParseMFAChallenge(body.MFAToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token, please log in again"})
+		return
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, challenge.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	// ✅ Wrong codes count against the same account lockout as wrong passwords
+	if !loginAllowed(c, user.Email) {
 		return
 	}
 
-	fmt.Println("Generated Token:", tokenString)
+	if err := services.VerifySecondFactor(user.ID, body.Code); err != nil {
+		loginFailed(c, user.Email, user.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":              "Invalid authentication code",
+			"attempts_remaining": services.MaxMFAChallengeAttempts - challenge.Attempts,
+		})
+		return
+	}
+
+	// ✅ A challenge starts one session, a replayed token gets nothing
+	if err := services.CompleteMFAChallenge(challenge.ID); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token, please log in again"})
+		return
+	}
+
+	completeLogin(c, user)
+}
+
+// loginAllowed writes a 429 with Retry-After if the account or client IP is locked out
+func loginAllowed(c *gin.Context, email string) bool {
+	err := services.CheckLoginAllowed(email, c.ClientIP())
+	if locked, ok := services.IsLoginLocked(err); ok {
+		retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       "Too many failed login attempts, try again later",
+			"retry_after": retryAfter,
+		})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+		return false
+	}
+	return true
+}
+
+// loginFailed counts a failed attempt (userID is 0 when the email isn't registered)
+func loginFailed(c *gin.Context, email string, userID uint) {
+	if err := services.RecordLoginFailure(email, c.ClientIP(), userID); err != nil {
+		log.Println("❌ Failed to record login attempt:", err)
+	}
+}
+
+// checkLoginGates runs the checks every login method shares once it knows
+// who the user is, so neither password login nor SSO can skip one. It
+// returns an MFA challenge token when the user still has to enter a code.
+func checkLoginGates(user model.User) (string, error) {
+	if user.DisabledAt != nil {
+		return "", services.ErrAccountDisabled
+	}
+
+	// Optionally block login until the email address is verified
+	if services.RequireEmailVerification() && user.EmailVerifiedAt == nil {
+		return "", services.ErrEmailNotVerified
+	}
+
+	// ✅ Users with an authenticator (every admin, when REQUIRE_ADMIN_MFA is on) need the second step
+	if services.HasTOTP(user.ID) {
+		return services.IssueMFAChallenge(user)
+	}
+	return "", nil
+}
+
+// startSession issues a short-lived access token plus a rotating refresh token and sets their cookies
+func startSession(c *gin.Context, user model.User) (services.TokenPair, error) {
+	if user.DisabledAt != nil {
+		return services.TokenPair{}, services.ErrAccountDisabled
+	}
+
+	pair, err := services.IssueTokenPair(user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		return pair, err
+	}
+
+	setAuthCookies(c, pair)
+	return pair, nil
+}
+
+// completeLogin starts a session for a user who passed every login step and returns its tokens
+func completeLogin(c *gin.Context, user model.User) {
+	pair, err := startSession(c, user)
+	if errors.Is(err, services.ErrAccountDisabled) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
 
-	// Set the JWT token in an HTTP-only cookie
-	c.SetCookie("Authorization", tokenString, 3600*24*30, "/", "", false, true)
+	// ✅ Only a finished login (password and, if enrolled, the second factor) clears the failures
+	if err := services.RecordLoginSuccess(user.Email); err != nil {
+		log.Println("❌ Failed to reset login attempts:", err)
+	}
 
-	// ✅ Return token in response body (for easy access in Postman)
+	// ✅ Return tokens in response body (for clients sending `Authorization: Bearer <token>`)
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Logged in successfully",
-		"token":   tokenString,
+		"message":       "Logged in successfully",
+		"token":         pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expires_at":    pair.ExpiresAt,
 		"user": gin.H{
 			"id":       user.ID,
 			"username": user.Username,
@@ -147,39 +292,191 @@ func Validate(c *gin.Context) {
 	})
 }
 
-// Logout function (clears the authentication cookie)
+// RefreshToken exchanges a refresh token (body or cookie) for a new token pair
+func RefreshToken(c *gin.Context) {
+	raw := refreshTokenFromRequest(c)
+	if raw == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing refresh token"})
+		return
+	}
+
+	pair, _, err := services.RotateRefreshToken(raw)
+	if err != nil {
+		clearAuthCookies(c)
+		if errors.Is(err, services.ErrRefreshTokenReused) || errors.Is(err, services.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	setAuthCookies(c, pair)
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expires_at":    pair.ExpiresAt,
+	})
+}
+
+// Logout function (revokes the session and clears the authentication cookies)
 func Logout(c *gin.Context) {
-	// Set the cookie to expire immediately
-	c.SetCookie("Authorization", "", -1, "/", "", false, true)
+	// Revoke by refresh token if we have one, otherwise by the access token's session
+	if raw := refreshTokenFromRequest(c); raw != "" {
+		services.RevokeRefreshToken(raw)
+	} else if access := accessTokenFromRequest(c); access != "" {
+		if claims, err := services.ParseAccessToken(access); err == nil {
+			if sid, ok := claims["sid"].(string); ok {
+				services.RevokeSession(sid)
+			}
+		}
+	}
+
+	// Set the cookies to expire immediately
+	clearAuthCookies(c)
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
-// GetAllUsers - Admin-only endpoint to fetch all users
+// accessTokenFromRequest reads the access token from a Bearer header (how
+// impersonation tokens are sent), falling back to the cookie
+func accessTokenFromRequest(c *gin.Context) string {
+	if scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	token, _ := c.Cookie("Authorization")
+	return token
+}
+
+// refreshTokenFromRequest reads the refresh token from the JSON body, falling back to the cookie
+func refreshTokenFromRequest(c *gin.Context) string {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if c.ShouldBindJSON(&body) == nil && body.RefreshToken != "" {
+		return body.RefreshToken
+	}
+
+	raw, _ := c.Cookie("RefreshToken")
+	return raw
+}
+
+// setAuthCookies stores both tokens in HTTP-only cookies
+func setAuthCookies(c *gin.Context, pair services.TokenPair) {
+	c.SetCookie("Authorization", pair.AccessToken, int(services.AccessTokenTTL.Seconds()), "/", "", false, true)
+	c.SetCookie("RefreshToken", pair.RefreshToken, int(services.RefreshTokenTTL.Seconds()), "/", "", false, true)
+}
+
+func clearAuthCookies(c *gin.Context) {
+	c.SetCookie("Authorization", "", -1, "/", "", false, true)
+	c.SetCookie("RefreshToken", "", -1, "/", "", false, true)
+}
+
+// GetAllUsers - Admin-only endpoint to list users (guarded by RequirePermission).
+// Supports ?q= (username/email search), ?role=, ?status=active|disabled, ?page= and ?per_page=.
 func GetAllUsers(c *gin.Context) {
-	// ✅ Get `user_id` from context (to verify admin access)
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	page = max(page, 1)
+	perPage = min(max(perPage, 1), 100)
+
+	query := database.DB.Model(&model.User{})
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		query = query.Where("username ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+	switch c.Query("status") {
+	case "":
+	case "active":
+		query = query.Where("disabled_at IS NULL")
+	case "disabled":
+		query = query.Where("disabled_at IS NOT NULL")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active or disabled"})
 		return
 	}
 
-	// ✅ Fetch the requesting user's details
-	var user model.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	var total int64
+	var users []model.User
+	query.Count(&total)
+	if err := query.Order("id").Offset((page - 1) * perPage).Limit(perPage).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+
+	// ✅ Never return raw rows, only the DTO
+	result := make([]model.UserResponse, 0, len(users))
+	for _, user := range users {
+		result = append(result, model.NewUserResponse(user))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":    result,
+		"page":     page,
+		"per_page": perPage,
+		"total":    total,
+	})
+}
+
+// SetUserRole - Admin-only endpoint to change another user's role
+func SetUserRole(c *gin.Context) {
+	var body struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// ✅ Check if the user is an admin
-	if user.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	if _, ok := model.RolePermissions[body.Role]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
 		return
 	}
 
-	// ✅ Fetch all users
-	var users []model.User
-	database.DB.Find(&users)
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	// ✅ Never leave the system without an admin
+	previous := user.Role
+	err := services.ChangeUserRole(user.ID, body.Role)
+	if errors.Is(err, services.ErrLastAdmin) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last admin"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+	user.Role = body.Role
+
+	actorID := c.GetUint("user_id")
+	services.Audit(services.AuditRoleChanged, &actorID, &user.ID, c.ClientIP(), previous+" -> "+body.Role)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated",
+		"user":    model.NewUserResponse(user),
+	})
+}
+
+// UnlockUser clears a user's failed login count and lockout (admin only)
+func UnlockUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	if err := services.UnlockAccount(user.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+		return
+	}
+
+	actorID := c.GetUint("user_id")
+	services.Audit(services.AuditAccountUnlocked, &actorID, &user.ID, c.ClientIP(), user.Email+" unlocked by an admin")
 
-	// ✅ Return list of users
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlocked"})
 }
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/middleware"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// roleRouter wires the signup and role routes the way main.go does
+func roleRouter() *gin.Engine {
+	r := gin.New()
+	r.POST("/signup", Signup)
+
+	protected := r.Group("/")
+	protected.Use(middleware.RequireAuth)
+	admin := protected.Group("/")
+	admin.Use(middleware.RequireScope(model.ScopeAdmin))
+	admin.PUT("/users/:id/role", middleware.RequirePermission(model.PermUsersManageRoles), SetUserRole)
+	return r
+}
+
+func TestSignupIgnoresRole(t *testing.T) {
+	setupTestDB(t)
+
+	w := doJSON(roleRouter(), http.MethodPost, "/signup", "", map[string]string{
+		"username": "mallory",
+		"email":    "mallory@example.com",
+		"password": "correct-Horse-battery-staple-42",
+		"role":     model.RoleAdmin,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup status = %d, want %d (body %s)", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	var user model.User
+	if err := database.DB.Where("email = ?", "mallory@example.com").First(&user).Error; err != nil {
+		t.Fatal("user was not created:", err)
+	}
+	if user.Role != model.RoleUser {
+		t.Fatalf("role = %q, want %q", user.Role, model.RoleUser)
+	}
+}
+
+func TestSetUserRoleRequiresAdmin(t *testing.T) {
+	setupTestDB(t)
+	setupSigningKey(t)
+
+	user := createTestUser(t, "bob", model.RoleUser)
+	target := createTestUser(t, "carol", model.RoleUser)
+
+	tests := []struct {
+		name   string
+		token  string
+		target model.User
+		want   int
+	}{
+		{"anonymous", "", target, http.StatusUnauthorized},
+		{"user promoting someone else", accessTokenFor(t, user), target, http.StatusForbidden},
+		{"user promoting themselves", accessTokenFor(t, user), user, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/users/" + fmt.Sprint(tt.target.ID) + "/role"
+			w := doJSON(roleRouter(), http.MethodPut, path, tt.token, map[string]string{"role": model.RoleAdmin})
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body.String())
+			}
+
+			var after model.User
+			database.DB.First(&after, tt.target.ID)
+			if after.Role != model.RoleUser {
+				t.Fatalf("role changed to %q", after.Role)
+			}
+		})
+	}
+}
+
+func TestSetUserRoleAsAdmin(t *testing.T) {
+	setupTestDB(t)
+	setupSigningKey(t)
+
+	admin := createTestUser(t, "alice", model.RoleAdmin)
+	target := createTestUser(t, "carol", model.RoleUser)
+	token := accessTokenFor(t, admin)
+
+	w := doJSON(roleRouter(), http.MethodPut, "/users/"+fmt.Sprint(target.ID)+"/role", token, map[string]string{"role": model.RoleAdmin})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var after model.User
+	database.DB.First(&after, target.ID)
+	if after.Role != model.RoleAdmin {
+		t.Fatalf("role = %q, want %q", after.Role, model.RoleAdmin)
+	}
+
+	// ✅ Unknown roles and IDs that aren't numbers are refused
+	if w := doJSON(roleRouter(), http.MethodPut, "/users/"+fmt.Sprint(target.ID)+"/role", token, map[string]string{"role": "root"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown role status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := doJSON(roleRouter(), http.MethodPut, "/users/1%20OR%201=1/role", token, map[string]string{"role": model.RoleUser}); w.Code != http.StatusBadRequest {
+		t.Fatalf("non-numeric ID status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+// mfaUser creates a user with a confirmed authenticator and returns a recovery code
+func mfaUser(t *testing.T, username string) (model.User, []string) {
+	t.Helper()
+
+	user := createTestUser(t, username, model.RoleUser)
+	now := time.Now()
+	database.DB.Create(&model.TOTPCredential{UserID: user.ID, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &now})
+	codes, err := services.RegenerateRecoveryCodes(user.ID)
+	if err != nil {
+		t.Fatal("create recovery codes:", err)
+	}
+	return user, codes
+}
+
+func TestLoginMFAChallengeIsSingleUse(t *testing.T) {
+	setupTestDB(t)
+	setupSigningKey(t)
+
+	user, codes := mfaUser(t, "dave")
+	challenge, err := services.IssueMFAChallenge(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.POST("/login/mfa", LoginMFA)
+
+	if w := doJSON(r, http.MethodPost, "/login/mfa", "", gin.H{"mfa_token": challenge, "code": codes[0]}); w.Code != http.StatusOK {
+		t.Fatalf("first use status = %d, want %d (body %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	// ✅ Even with another valid code, the same challenge doesn't start a second session
+	if w := doJSON(r, http.MethodPost, "/login/mfa", "", gin.H{"mfa_token": challenge, "code": codes[1]}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("replay status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestLoginMFAChallengeAttemptLimit(t *testing.T) {
+	setupTestDB(t)
+	setupSigningKey(t)
+
+	user, codes := mfaUser(t, "erin")
+	challenge, err := services.IssueMFAChallenge(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.POST("/login/mfa", LoginMFA)
+
+	for i := 0; i < services.MaxMFAChallengeAttempts; i++ {
+		services.UnlockAccount(user.Email) // As if the login delay had passed
+		if w := doJSON(r, http.MethodPost, "/login/mfa", "", gin.H{"mfa_token": challenge, "code": "000000"}); w.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong code %d status = %d, want %d", i, w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	// ✅ Out of attempts: a correct code no longer helps
+	services.UnlockAccount(user.Email)
+	w := doJSON(r, http.MethodPost, "/login/mfa", "", gin.H{"mfa_token": challenge, "code": codes[0]})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status after limit = %d, want %d (body %s)", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+
+	var used int64
+	database.DB.Model(&model.RecoveryCode{}).Where("user_id = ? AND used_at IS NOT NULL", user.ID).Count(&used)
+	if used != 0 {
+		t.Fatalf("%d recovery codes were spent on an exhausted challenge", used)
+	}
+}
+
+func TestLoginFailuresClearOnlyAfterFullLogin(t *testing.T) {
+	setupTestDB(t)
+	setupSigningKey(t)
+
+	const password = "correct-Horse-battery-staple-42"
+	user, codes := mfaUser(t, "ivan")
+	hash, _ := services.HashPassword(password)
+	database.DB.Model(&user).Update("password_hash", hash)
+
+	r := gin.New()
+	r.POST("/login", Login)
+	r.POST("/login/mfa", LoginMFA)
+
+	failures := func() int {
+		state, err := services.LoginAttempts.Get("account:" + user.Email)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return state.Failures
+	}
+
+	if w := doJSON(r, http.MethodPost, "/login", "", gin.H{"email": user.Email, "password": "wrong"}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password status = %d", w.Code)
+	}
+
+	// ✅ The right password alone doesn't clear the failures, the second factor is still missing
+	w := doJSON(r, http.MethodPost, "/login", "", gin.H{"email": user.Email, "password": password})
+	if w.Code != http.StatusOK || failures() != 1 {
+		t.Fatalf("password step: status %d, %d failures, want 200 and 1 failure", w.Code, failures())
+	}
+	var challenge struct {
+		MFAToken string `json:"mfa_token"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &challenge)
+
+	if w := doJSON(r, http.MethodPost, "/login/mfa", "", gin.H{"mfa_token": challenge.MFAToken, "code": codes[0]}); w.Code != http.StatusOK {
+		t.Fatalf("MFA step status = %d (body %s)", w.Code, w.Body.String())
+	}
+	if failures() != 0 {
+		t.Fatalf("%d failures after a full login, want 0", failures())
+	}
+
+	// ✅ Disabled accounts never get their failures cleared
+	services.RecordLoginFailure(user.Email, "127.0.0.1", user.ID)
+	now := time.Now()
+	database.DB.Model(&user).Update("disabled_at", now)
+	database.DB.Where("user_id = ?", user.ID).Delete(&model.TOTPCredential{})
+
+	if w := doJSON(r, http.MethodPost, "/login", "", gin.H{"email": user.Email, "password": password}); w.Code != http.StatusForbidden {
+		t.Fatalf("disabled account status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if failures() != 1 {
+		t.Fatalf("%d failures after a disabled login, want 1", failures())
+	}
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/tarun05rawat/go-task-management/model"
 	"gorm.io/driver/postgres"
@@ -11,14 +12,19 @@ import (
 
 var DB *gorm.DB
 
+// DSN returns the PostgreSQL connection string (DATABASE_URL overrides the local default)
+func DSN() string {
+	if dsn := os.Getenv("DATABASE_URL"); dsn != "" {
+		return dsn
+	}
+	return "host=localhost user=tarunrawat dbname=task_management port=5432 sslmode=disable"
+}
+
 func ConnectToDb() {
 	var err error
 
-	// PostgreSQL connection string (update accordingly)
-	dsn := "host=localhost user=tarunrawat dbname=task_management port=5432 sslmode=disable"
-
 	// Open DB connection
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(DSN()), &gorm.Config{})
 	if err != nil {
 		log.Fatal("❌ Failed to connect to database:", err)
 	}
@@ -33,10 +39,37 @@ func ConnectToDb() {
 	}
 
 	// AutoMigrate models (Ensure all required tables exist)
-	err = DB.AutoMigrate(&model.User{}, &model.UserData{}, &model.Task{}) // ✅ Added Task model
+	err = DB.AutoMigrate(
+		&model.User{}, &model.UserData{}, &model.Task{}, // ✅ Added Task model
+		&model.TaskWatcher{}, &model.WatchInvite{}, &model.NotificationPreference{}, &model.Notification{},
+		&model.OutboxEmail{},
+		&model.Webhook{}, &model.WebhookDelivery{},
+		&model.SavedView{},
+		&model.Session{}, &model.RefreshToken{}, &model.APIToken{},
+		&model.UserToken{}, &model.TOTPCredential{}, &model.RecoveryCode{}, &model.MFAChallenge{},
+		&model.UserIdentity{},
+		&model.LoginAttempt{}, &model.AuditLog{},
+		&model.DataExport{},
+		&model.CalendarFeed{},
+	)
 	if err != nil {
 		log.Fatal("❌ Failed to auto-migrate database:", err)
 	}
 
+	// ✅ Webhook response bodies are no longer kept, drop the ones stored before
+	if DB.Migrator().HasColumn(&model.WebhookDelivery{}, "response_body") {
+		if err := DB.Migrator().DropColumn(&model.WebhookDelivery{}, "response_body"); err != nil {
+			log.Fatal("❌ Failed to drop webhook response bodies:", err)
+		}
+	}
+
+	// ✅ Watchers and assignees used to be added without asking them, drop the ones who never agreed to work with the owner
+	err = DB.Exec(`DELETE FROM task_watchers w WHERE w.user_id <> w.owner_id AND NOT EXISTS (
+		SELECT 1 FROM watch_invites i
+		WHERE i.owner_id = w.owner_id AND i.user_id = w.user_id AND i.accepted_at IS NOT NULL)`).Error
+	if err != nil {
+		log.Fatal("❌ Failed to remove unconfirmed watchers:", err)
+	}
+
 	fmt.Println("✅ Database connected and migrated successfully!")
 }
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// calendarRefreshInterval is how often subscribed calendar apps are asked to poll
+const calendarRefreshInterval = 15 * time.Minute
+
+// ✅ List Calendar Feeds of the Logged-In User (Never the URLs)
+func GetCalendarFeeds(c *gin.Context) {
+	var feeds []model.CalendarFeed
+	database.DB.Where("user_id = ? AND revoked_at IS NULL", c.GetUint("user_id")).Order("id").Find(&feeds)
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+// ✅ Create a Calendar Feed (The Secret URL Is Only Returned Here)
+func CreateCalendarFeed(c *gin.Context) {
+	var body struct {
+		Name             string `json:"name"`
+		IncludeCompleted bool   `json:"include_completed"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" {
+		body.Name = "Tasks"
+	}
+
+	feed, raw, err := services.CreateCalendarFeed(c.GetUint("user_id"), body.Name, body.IncludeCompleted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Subscribe to this URL in your calendar app, it won't be shown again",
+		"url":     services.CalendarFeedURL(raw),
+		"feed":    feed,
+	})
+}
+
+// ✅ Revoke a Calendar Feed (Subscribed Calendars Stop Updating)
+func RevokeCalendarFeed(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+
+	result := database.DB.Model(&model.CalendarFeed{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, c.GetUint("user_id")).
+		Update("revoked_at", time.Now())
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked"})
+}
+
+// ✅ Serve a Calendar Feed (Public, the Token in the URL Is the Credential)
+//
+// Tasks with a due date are listed both as a VTODO and as a VEVENT at the
+// due time, since many calendar apps only show one of the two. The feed is
+// built on every request, so it follows task changes at the client's next poll.
+func ServeCalendarFeed(c *gin.Context) {
+	raw, ok := strings.CutSuffix(c.Param("file"), ".ics")
+	if !ok || !strings.HasPrefix(raw, model.CalendarFeedPrefix) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	feed, user, err := services.AuthenticateCalendarFeed(raw)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	query := database.DB.Model(&model.Task{}).Where("user_id = ? AND due_date IS NOT NULL", user.ID)
+	if !feed.IncludeCompleted {
+		query = query.Where("status <> ?", model.StatusCompleted)
+	}
+	rows, err := query.Order("due_date").Order("task_id").Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar feed"})
+		return
+	}
+	defer rows.Close()
+
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Status(http.StatusOK)
+
+	ics := newICSWriter(c.Writer, feed.Name)
+	ics.RefreshEvery(calendarRefreshInterval)
+	err = eachExportedTask(c, rows, ics.w.Flush, func(task model.Task) error {
+		ics.WriteTodo(task)
+		ics.WriteEvent(task)
+		return nil
+	})
+	if err == nil {
+		err = ics.Close()
+	}
+	if err != nil {
+		log.Println("❌ Calendar feed interrupted:", err)
+	}
+}
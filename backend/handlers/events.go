@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// How often an idle stream sends a ping so proxies don't close it
+const streamHeartbeat = 25 * time.Second
+
+// ✅ Stream Task & Attachment Events (Server-Sent Events)
+func StreamEvents(c *gin.Context) {
+	events, unsubscribe := services.SubscribeEvents(c.GetUint("user_id"))
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // ✅ Stop nginx from buffering the stream
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event := <-events:
+			c.SSEvent(event.Event, event)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
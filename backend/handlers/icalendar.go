@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// icsWriter writes tasks as an RFC 5545 calendar, one component at a time
+type icsWriter struct {
+	w   *bufio.Writer
+	now string
+}
+
+func newICSWriter(w io.Writer, name string) *icsWriter {
+	ics := &icsWriter{w: bufio.NewWriter(w), now: time.Now().UTC().Format(icsTimeFormat)}
+	ics.line("BEGIN:VCALENDAR")
+	ics.line("VERSION:2.0")
+	ics.line("PRODID:-//go-task-management//Tasks//EN")
+	ics.line("CALSCALE:GREGORIAN")
+	ics.line("X-WR-CALNAME:" + icsEscape(name))
+	return ics
+}
+
+// WriteTodo adds the task as a VTODO, with DUE when it has a due date
+func (ics *icsWriter) WriteTodo(task model.Task) {
+	ics.line("BEGIN:VTODO")
+	ics.taskProperties(task, "todo")
+	if task.DueDate != nil {
+		ics.line("DUE:" + task.DueDate.UTC().Format(icsTimeFormat))
+	}
+	if task.Status == model.StatusCompleted {
+		ics.line("STATUS:COMPLETED")
+		ics.line("COMPLETED:" + task.UpdatedAt.UTC().Format(icsTimeFormat))
+	} else {
+		ics.line("STATUS:NEEDS-ACTION")
+	}
+	ics.line("END:VTODO")
+}
+
+// WriteEvent adds a task with a due date as a zero-length VEVENT at the due
+// time, for calendar apps that don't show VTODOs
+func (ics *icsWriter) WriteEvent(task model.Task) {
+	if task.DueDate == nil {
+		return
+	}
+	due := task.DueDate.UTC().Format(icsTimeFormat)
+
+	ics.line("BEGIN:VEVENT")
+	ics.taskProperties(task, "event")
+	ics.line("DTSTART:" + due)
+	ics.line("DTEND:" + due)
+	ics.line("TRANSP:TRANSPARENT")
+	ics.line("END:VEVENT")
+}
+
+// RefreshEvery tells subscribed calendar apps how often to poll. It must be
+// called before any tasks are written.
+func (ics *icsWriter) RefreshEvery(d time.Duration) {
+	minutes := int(d.Minutes())
+	ics.line(fmt.Sprintf("REFRESH-INTERVAL;VALUE=DURATION:PT%dM", minutes))
+	ics.line(fmt.Sprintf("X-PUBLISHED-TTL:PT%dM", minutes))
+}
+
+// Close ends the calendar and flushes what's left
+func (ics *icsWriter) Close() error {
+	ics.line("END:VCALENDAR")
+	return ics.w.Flush()
+}
+
+// taskProperties writes the properties describing the task itself. The
+// VTODO and VEVENT of a task need different UIDs to appear in one calendar.
+func (ics *icsWriter) taskProperties(task model.Task, kind string) {
+	uid := fmt.Sprintf("task-%d-%d", task.UserID, task.TaskID)
+	if kind != "todo" {
+		uid += "-" + kind
+	}
+	ics.line("UID:" + uid + "@go-task-management")
+	ics.line("DTSTAMP:" + ics.now)
+	ics.line("CREATED:" + task.CreatedAt.UTC().Format(icsTimeFormat))
+	ics.line("LAST-MODIFIED:" + task.UpdatedAt.UTC().Format(icsTimeFormat))
+	ics.line("SUMMARY:" + icsEscape(task.Title))
+	if task.Description != "" {
+		ics.line("DESCRIPTION:" + icsEscape(task.Description))
+	}
+}
+
+// line writes a content line, folded at 75 octets without splitting characters
+func (ics *icsWriter) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		ics.w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	ics.w.WriteString(s + "\r\n")
+}
+
+// icsEscape escapes a TEXT value
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
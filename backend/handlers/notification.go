@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// ✅ Get Notifications for the Logged-In User (Newest First)
+func GetNotifications(c *gin.Context) {
+	var notifications []model.Notification
+
+	query := database.DB.Where("user_id = ?", c.GetUint("user_id"))
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+	query.Order("created_at DESC").Limit(100).Find(&notifications)
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// ✅ Mark a Notification as Read
+func MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	result := database.DB.Model(&model.Notification{}).
+		Where("id = ? AND user_id = ?", id, c.GetUint("user_id")).
+		Update("read_at", time.Now())
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// ✅ Get Notification Preferences
+func GetNotificationPreferences(c *gin.Context) {
+	c.JSON(http.StatusOK, services.PreferencesFor(c.GetUint("user_id")))
+}
+
+// ✅ Update Notification Preferences (Omitted Fields Keep Their Current Value)
+func UpdateNotificationPreferences(c *gin.Context) {
+	pref := services.PreferencesFor(c.GetUint("user_id"))
+
+	if err := c.ShouldBindJSON(&pref); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pref.UserID = c.GetUint("user_id")
+
+	if err := database.DB.Save(&pref).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
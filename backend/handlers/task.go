@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tarun05rawat/go-task-management/database"
 	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func CreateTask(c *gin.Context) {
@@ -33,26 +39,45 @@ func CreateTask(c *gin.Context) {
 	// ✅ Assign `UserID` to the task
 	task.UserID = userIDUint
 
-	// ✅ Fetch last task ID for this user
-	var lastTask model.Task
-	result := database.DB.Where("user_id = ?", task.UserID).Order("task_id DESC").First(&lastTask)
-
-	// ✅ If no previous task exists, start from 1
-	if result.Error != nil {
-		task.TaskID = 1
-	} else {
-		task.TaskID = lastTask.TaskID + 1
-	}
-
-	// ✅ Save Task
-	if err := database.DB.Create(&task).Error; err != nil {
+	// ✅ Save Task with the next ID in this user's sequence
+	task.Version = 1
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if task.TaskID, err = nextTaskID(tx, task.UserID); err != nil {
+			return err
+		}
+		return tx.Create(&task).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task", "details": err.Error()})
 		return
 	}
 
+	// ✅ Creator automatically watches the task
+	if err := services.WatchTask(task.UserID, task.TaskID, task.UserID, services.WatchReasonCreator); err != nil {
+		log.Println("❌ Failed to add creator as watcher:", err)
+	}
+
+	services.EmitEvent(task.UserID, services.WebhookTaskCreated, task)
+
+	c.Header("ETag", taskETag(task))
 	c.JSON(http.StatusCreated, task)
 }
 
+// nextTaskID returns the ID the user's next task gets: one past their
+// highest, starting from 1. Task IDs are only unique per user. It locks the
+// user row until tx ends, so concurrent creates and imports can't hand out
+// the same ID; call it in the transaction that inserts the task.
+func nextTaskID(tx *gorm.DB, userID uint) (uint, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.User{}, userID).Error; err != nil {
+		return 0, err
+	}
+
+	var last uint
+	err := tx.Model(&model.Task{}).Where("user_id = ?", userID).Select("COALESCE(MAX(task_id), 0)").Scan(&last).Error
+	return last + 1, err
+}
+
 // ✅ Get All Tasks (For Logged-In User)
 func GetTasks(c *gin.Context) {
 	var tasks []model.Task
@@ -64,8 +89,15 @@ func GetTasks(c *gin.Context) {
 		return
 	}
 
+	// ✅ Optional filters (?status=&due_after=&due_before=&q=&sort=)
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// ✅ Fetch only tasks that belong to the user
-	database.DB.Where("user_id = ?", userID).Find(&tasks)
+	applyTaskFilter(database.DB.Where("user_id = ?", userID), filter).Find(&tasks)
 
 	c.JSON(http.StatusOK, tasks)
 }
@@ -95,6 +127,11 @@ func GetTaskByID(c *gin.Context) {
 		return
 	}
 
+	// ✅ The client's cached copy is still current
+	if notModified(c, task) {
+		return
+	}
+
 	c.JSON(http.StatusOK, task)
 }
 
@@ -123,19 +160,82 @@ func UpdateTask(c *gin.Context) {
 		return
 	}
 
+	// ✅ Refuse edits made against an older version
+	if !checkIfMatch(c, task) {
+		return
+	}
+
+	// ✅ Remember the task as it was, for watchers and server-owned fields
+	previous := task
+
 	// ✅ Bind new task data from request body
 	if err := c.ShouldBindJSON(&task); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// ✅ Save the updated task
-	database.DB.Save(&task)
+	// ✅ Owner, ID and creation time belong to the server, whatever the body says
+	task.UserID = previous.UserID
+	task.TaskID = previous.TaskID
+	task.CreatedAt = previous.CreatedAt
+	task.Version = previous.Version
 
-	// ✅ Return success response
+	// ✅ A new due date deserves a new reminder
+	if !sameDueDate(previous.DueDate, task.DueDate) {
+		task.ReminderSentAt = nil
+	}
+
+	// ✅ Save the updated task, unless someone else changed it since we read it
+	saved, err := updateTaskVersion(task, map[string]any{
+		"title":            task.Title,
+		"description":      task.Description,
+		"status":           task.Status,
+		"due_date":         task.DueDate,
+		"reminder_sent_at": task.ReminderSentAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task", "details": err.Error()})
+		return
+	}
+	if !saved {
+		taskChanged(c, task)
+		return
+	}
+	database.DB.Where("user_id = ? AND task_id = ?", task.UserID, task.TaskID).First(&task)
+
+	notifyTaskUpdated(c.GetUint("user_id"), previous, task)
+
+	c.Header("ETag", taskETag(task))
 	c.JSON(http.StatusOK, task)
 }
 
+// notifyTaskUpdated tells watchers about the changes they subscribed to and
+// fires the task.updated webhook
+func notifyTaskUpdated(actorID uint, previous, task model.Task) {
+	if task.Status != previous.Status {
+		message := fmt.Sprintf("Task %q moved from %s to %s", task.Title, previous.Status, task.Status)
+		if err := services.NotifyWatchers(task.UserID, task.TaskID, actorID, services.EventStatusChange, message); err != nil {
+			log.Println("❌ Failed to notify watchers:", err)
+		}
+	}
+	if !sameDueDate(previous.DueDate, task.DueDate) {
+		message := fmt.Sprintf("Due date of task %q changed", task.Title)
+		if err := services.NotifyWatchers(task.UserID, task.TaskID, actorID, services.EventDueDate, message); err != nil {
+			log.Println("❌ Failed to notify watchers:", err)
+		}
+	}
+
+	services.EmitEvent(task.UserID, services.WebhookTaskUpdated, task)
+}
+
+// sameDueDate reports whether two optional due dates are equal
+func sameDueDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
 // ✅ Delete Task (Ensuring User Can Only Delete Their Own Tasks)
 func DeleteTask(c *gin.Context) {
 	var task model.Task
@@ -161,38 +261,28 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	// ✅ Delete Task
-	database.DB.Delete(&task)
-
-	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
-}
-
-// ✅ Get All Users (Admin Only)
-func GetAllUsers(c *gin.Context) {
-	// ✅ Get `user_id` from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	if !checkIfMatch(c, task) {
 		return
 	}
 
-	// ✅ Fetch the requesting user's details
-	var user model.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	// ✅ Delete Task (only the version the client saw, when it named one)
+	query := database.DB.Where("user_id = ? AND task_id = ?", task.UserID, task.TaskID)
+	if c.GetHeader("If-Match") != "" {
+		query = query.Where("version = ?", task.Version)
+	}
+	result = query.Delete(&model.Task{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
 		return
 	}
-
-	// ✅ Check if the user is an admin
-	if user.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	if result.RowsAffected == 0 {
+		taskChanged(c, task)
 		return
 	}
+	database.DB.Where("owner_id = ? AND task_id = ?", task.UserID, task.TaskID).Delete(&model.TaskWatcher{})
+	database.DB.Where("owner_id = ? AND task_id = ?", task.UserID, task.TaskID).Delete(&model.WatchInvite{})
 
-	// ✅ Fetch all users
-	var users []model.User
-	database.DB.Find(&users)
+	services.EmitEvent(task.UserID, services.WebhookTaskDeleted, task)
 
-	// ✅ Return list of users
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
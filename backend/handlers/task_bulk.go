@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxBulkTasks = 500
+
+// Operations POST /tasks/bulk can apply
+const (
+	bulkSetStatus  = "set_status"   // Needs "status"
+	bulkSetDueDate = "set_due_date" // Needs "due_date", null clears it
+	bulkAssign     = "assign"       // Needs "user_id" of a collaborator, who then watches the task as its assignee
+	bulkUnassign   = "unassign"     // Needs "user_id"
+	bulkDelete     = "delete"
+)
+
+var bulkOperations = []string{bulkSetStatus, bulkSetDueDate, bulkAssign, bulkUnassign, bulkDelete}
+
+// Per-task outcomes of a bulk operation
+const (
+	bulkResultUpdated   = "updated"
+	bulkResultUnchanged = "unchanged"
+	bulkResultDeleted   = "deleted"
+	bulkResultNotFound  = "not_found"
+)
+
+type bulkResult struct {
+	ID     uint   `json:"id"`
+	Result string `json:"result"`
+}
+
+// ✅ Apply One Operation to Many Tasks
+//
+// Everything runs in one transaction with the tasks locked, so either all
+// found tasks change or none do. IDs that don't exist (or aren't the
+// caller's) are reported as not_found without failing the rest.
+func BulkUpdateTasks(c *gin.Context) {
+	var body struct {
+		TaskIDs   []uint     `json:"task_ids" binding:"required"`
+		Operation string     `json:"operation" binding:"required"`
+		Status    string     `json:"status"`
+		DueDate   *time.Time `json:"due_date"`
+		UserID    uint       `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !slices.Contains(bulkOperations, body.Operation) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown operation: " + body.Operation, "operations": bulkOperations})
+		return
+	}
+
+	// ✅ Keep the caller's order, drop repeats
+	var ids []uint
+	for _, id := range body.TaskIDs {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > maxBulkTasks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("task_ids must list between 1 and %d tasks", maxBulkTasks)})
+		return
+	}
+
+	switch body.Operation {
+	case bulkSetStatus:
+		body.Status = strings.TrimSpace(body.Status)
+		if body.Status == "" || len(body.Status) > maxStatusLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("status is required and must be at most %d characters", maxStatusLength)})
+			return
+		}
+	case bulkAssign, bulkUnassign:
+		if body.UserID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+			return
+		}
+	}
+
+	userID := c.GetUint("user_id")
+
+	// ✅ Only people who agreed to work with the caller can be handed tasks, and
+	// the same answer for strangers and unknown IDs reveals nothing about either
+	if body.Operation == bulkAssign && !services.IsCollaborator(userID, body.UserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id must be you or someone who accepted an invitation to watch one of your tasks"})
+		return
+	}
+
+	var before, after []model.Task // Tasks the operation changed, as they were and as they are now
+	results := map[uint]string{}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var tasks []model.Task
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND task_id IN ?", userID, ids).
+			Order("task_id").Find(&tasks).Error
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			results[task.TaskID] = bulkResultUnchanged
+		}
+
+		before, after, err = applyBulkOperation(tx, userID, tasks, body.Operation, body.Status, body.DueDate, body.UserID)
+		for _, task := range before {
+			results[task.TaskID] = bulkResultUpdated
+			if body.Operation == bulkDelete {
+				results[task.TaskID] = bulkResultDeleted
+			}
+		}
+		return err
+	})
+	if err != nil {
+		log.Println("❌ Bulk task operation failed:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bulk operation failed, no tasks were changed"})
+		return
+	}
+
+	// ✅ Same side effects as the single-task endpoints, once the changes are committed
+	for i, task := range after {
+		switch body.Operation {
+		case bulkSetStatus, bulkSetDueDate:
+			notifyTaskUpdated(userID, before[i], task)
+		case bulkDelete:
+			services.EmitEvent(task.UserID, services.WebhookTaskDeleted, task)
+		}
+	}
+	if body.Operation == bulkAssign {
+		services.NotifyAssigned(after, c.MustGet("user").(model.User), body.UserID) // One email, not one per task
+	}
+
+	response := make([]bulkResult, 0, len(ids))
+	counts := map[string]int{}
+	for _, id := range ids {
+		result, ok := results[id]
+		if !ok {
+			result = bulkResultNotFound
+		}
+		counts[result]++
+		response = append(response, bulkResult{ID: id, Result: result})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"operation": body.Operation,
+		"results":   response,
+		"counts":    counts,
+	})
+}
+
+// applyBulkOperation changes the tasks inside the transaction and returns the
+// ones it actually changed, before and after
+func applyBulkOperation(tx *gorm.DB, userID uint, tasks []model.Task, operation, status string, dueDate *time.Time, assigneeID uint) ([]model.Task, []model.Task, error) {
+	var before, after []model.Task
+	now := time.Now()
+
+	switch operation {
+	case bulkSetStatus, bulkSetDueDate:
+		updates := map[string]any{"updated_at": now, "version": gorm.Expr("version + 1")}
+		if operation == bulkSetStatus {
+			updates["status"] = status
+		} else {
+			updates["due_date"] = dueDate
+			updates["reminder_sent_at"] = nil // ✅ A new due date deserves a new reminder
+		}
+
+		var changed []uint
+		for _, task := range tasks {
+			updated := task
+			updated.UpdatedAt = now
+			updated.Version++
+			if operation == bulkSetStatus {
+				if task.Status == status {
+					continue
+				}
+				updated.Status = status
+			} else {
+				if sameDueDate(task.DueDate, dueDate) {
+					continue
+				}
+				updated.DueDate = dueDate
+				updated.ReminderSentAt = nil
+			}
+			before, after = append(before, task), append(after, updated)
+			changed = append(changed, task.TaskID)
+		}
+		if len(changed) == 0 {
+			return nil, nil, nil
+		}
+		err := tx.Model(&model.Task{}).Where("user_id = ? AND task_id IN ?", userID, changed).Updates(updates).Error
+		return before, after, err
+
+	case bulkAssign, bulkUnassign:
+		var ids, assigned []uint
+		for _, task := range tasks {
+			ids = append(ids, task.TaskID)
+		}
+		if len(ids) == 0 {
+			return nil, nil, nil
+		}
+		err := tx.Model(&model.TaskWatcher{}).
+			Where("owner_id = ? AND user_id = ? AND reason = ? AND task_id IN ?", userID, assigneeID, services.WatchReasonAssignee, ids).
+			Pluck("task_id", &assigned).Error
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var changed []uint
+		var watchers []model.TaskWatcher
+		for _, task := range tasks {
+			if slices.Contains(assigned, task.TaskID) == (operation == bulkAssign) {
+				continue
+			}
+			before, after = append(before, task), append(after, task)
+			changed = append(changed, task.TaskID)
+			watchers = append(watchers, model.TaskWatcher{OwnerID: userID, TaskID: task.TaskID, UserID: assigneeID, Reason: services.WatchReasonAssignee})
+		}
+		if len(changed) == 0 {
+			return nil, nil, nil
+		}
+
+		if operation == bulkUnassign {
+			err = tx.Where("owner_id = ? AND user_id = ? AND reason = ? AND task_id IN ?", userID, assigneeID, services.WatchReasonAssignee, changed).
+				Delete(&model.TaskWatcher{}).Error
+			return before, after, err
+		}
+		// Someone already watching for another reason is now watching as the assignee
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "owner_id"}, {Name: "task_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason"}),
+		}).Create(&watchers).Error
+		return before, after, err
+
+	case bulkDelete:
+		if len(tasks) == 0 {
+			return nil, nil, nil
+		}
+		var deleted []uint
+		for _, task := range tasks {
+			deleted = append(deleted, task.TaskID)
+		}
+		if err := tx.Where("user_id = ? AND task_id IN ?", userID, deleted).Delete(&model.Task{}).Error; err != nil {
+			return nil, nil, err
+		}
+		if err := tx.Where("owner_id = ? AND task_id IN ?", userID, deleted).Delete(&model.TaskWatcher{}).Error; err != nil {
+			return nil, nil, err
+		}
+		err := tx.Where("owner_id = ? AND task_id IN ?", userID, deleted).Delete(&model.WatchInvite{}).Error
+		return tasks, tasks, err
+	}
+
+	return nil, nil, nil
+}
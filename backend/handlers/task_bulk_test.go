@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+func TestBulkAssignNeedsAcceptedInvitation(t *testing.T) {
+	setupTestDB(t)
+	owner := createTestUser(t, "owner")
+	other := createTestUser(t, "other")
+	for _, title := range []string{"One", "Two", "Three"} {
+		createTestTask(t, owner, title)
+	}
+
+	r := routerAs(owner)
+	r.POST("/tasks/bulk", BulkUpdateTasks)
+	assign := func(userID uint) map[string]any {
+		return map[string]any{"task_ids": []uint{1, 2, 3}, "operation": "assign", "user_id": userID}
+	}
+
+	// ✅ A stranger, someone the owner made a watcher and an unknown ID all look the same
+	database.DB.Create(&model.TaskWatcher{OwnerID: owner.ID, TaskID: 1, UserID: other.ID, Reason: "manual"})
+	stranger := doJSON(r, http.MethodPost, "/tasks/bulk", assign(other.ID))
+	unknown := doJSON(r, http.MethodPost, "/tasks/bulk", assign(9999))
+	if stranger.Code != http.StatusBadRequest || unknown.Code != stranger.Code || unknown.Body.String() != stranger.Body.String() {
+		t.Fatalf("stranger = %d %s, unknown = %d %s; want the same 400", stranger.Code, stranger.Body, unknown.Code, unknown.Body)
+	}
+
+	now := time.Now()
+	database.DB.Create(&model.WatchInvite{OwnerID: owner.ID, TaskID: 1, UserID: other.ID, AcceptedAt: &now})
+	if w := doJSON(r, http.MethodPost, "/tasks/bulk", assign(other.ID)); w.Code != http.StatusOK {
+		t.Fatalf("assigning a collaborator = %d %s, want 200", w.Code, w.Body)
+	}
+
+	var emails []model.OutboxEmail
+	database.DB.Where(`"to" = ?`, other.Email).Find(&emails)
+	if len(emails) != 1 || !strings.Contains(emails[0].Subject, "3 tasks") {
+		t.Fatalf("emails to the assignee = %+v, want one summary of 3 tasks", emails)
+	}
+	var notifications int64
+	database.DB.Model(&model.Notification{}).Where("user_id = ? AND event = ?", other.ID, "assigned").Count(&notifications)
+	if notifications != 3 {
+		t.Fatalf("assignment notifications = %d, want 3", notifications)
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+)
+
+// requireIfMatch makes If-Match mandatory on task writes (428 without it).
+// Off by default so clients that don't send it yet keep working.
+var requireIfMatch = os.Getenv("REQUIRE_IF_MATCH") == "true"
+
+// taskETag is the strong validator of a task's current version
+func taskETag(task model.Task) string {
+	return fmt.Sprintf(`"%d"`, task.Version)
+}
+
+// etagMatches checks a header value (a list of tags or "*") against an ETag.
+// weak allows W/ tags, as If-None-Match does; If-Match needs strong ones.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified answers 304 when the client's cached copy (If-None-Match) is current
+func notModified(c *gin.Context, task model.Task) bool {
+	etag := taskETag(task)
+	c.Header("ETag", etag)
+
+	header := c.GetHeader("If-None-Match")
+	if header == "" || !etagMatches(header, etag, true) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// checkIfMatch stops a write made against an outdated copy of the task:
+// 412 if If-Match names another version, 428 if it's missing but required
+func checkIfMatch(c *gin.Context, task model.Task) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if requireIfMatch {
+			c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header with the task's ETag is required"})
+			return false
+		}
+		return true
+	}
+
+	if !etagMatches(header, taskETag(task), false) {
+		taskChanged(c, task)
+		return false
+	}
+	return true
+}
+
+// taskChanged answers 412 with the current task, so the client can redo its
+// change on top, or 404 if the task was deleted in the meantime
+func taskChanged(c *gin.Context, task model.Task) {
+	var current model.Task
+	result := database.DB.Where("user_id = ? AND task_id = ?", task.UserID, task.TaskID).Limit(1).Find(&current)
+	if result.Error == nil && result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or does not belong to you"})
+		return
+	}
+	if result.Error == nil {
+		task = current
+	}
+
+	c.Header("ETag", taskETag(task))
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Task was changed by someone else, reload it and try again", "task": task})
+}
+
+// updateTaskVersion writes the updates only if the task is still at the given
+// version, and bumps the version. It reports false if someone got there first.
+func updateTaskVersion(task model.Task, updates map[string]any) (bool, error) {
+	updates["version"] = gorm.Expr("version + 1")
+	result := database.DB.Model(&model.Task{}).
+		Where("user_id = ? AND task_id = ? AND version = ?", task.UserID, task.TaskID, task.Version).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
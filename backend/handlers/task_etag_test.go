@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+)
+
+// taskRouter wires the single-task routes the way main.go does
+func taskRouter(user model.User) *gin.Engine {
+	r := routerAs(user)
+	r.GET("/tasks/:id", GetTaskByID)
+	r.PUT("/tasks/:id", UpdateTask)
+	r.PATCH("/tasks/:id", PatchTask)
+	r.DELETE("/tasks/:id", DeleteTask)
+	return r
+}
+
+// raceBefore runs sql once, right before the next task write reaches the
+// database: another client changing the task after the handler read it
+func raceBefore(t *testing.T, write string, sql string) {
+	t.Helper()
+
+	var once sync.Once
+	race := func(tx *gorm.DB) {
+		if tx.Statement.Table != "tasks" {
+			return
+		}
+		once.Do(func() {
+			if err := database.DB.Exec(sql).Error; err != nil {
+				t.Error("race:", err)
+			}
+		})
+	}
+
+	var err error
+	switch write {
+	case "update":
+		err = database.DB.Callback().Update().Before("gorm:update").Register("test:race", race)
+	case "delete":
+		err = database.DB.Callback().Delete().Before("gorm:delete").Register("test:race", race)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetTaskIfNoneMatch(t *testing.T) {
+	setupTestDB(t)
+	user := createTestUser(t, "reader")
+	createTestTask(t, user, "Cached")
+	r := taskRouter(user)
+
+	w := doJSON(r, http.MethodGet, "/tasks/1", nil)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("GET = %d with ETag %q, want 200 with \"1\"", w.Code, w.Header().Get("ETag"))
+	}
+
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{`"1"`, http.StatusNotModified},
+		{`W/"1"`, http.StatusNotModified},
+		{`"0", "1"`, http.StatusNotModified},
+		{`*`, http.StatusNotModified},
+		{`"2"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := doJSON(r, http.MethodGet, "/tasks/1", nil, "If-None-Match", tt.header)
+		if w.Code != tt.want {
+			t.Errorf("If-None-Match %s = %d, want %d", tt.header, w.Code, tt.want)
+		}
+		if tt.want == http.StatusNotModified && w.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: 304 with a body", tt.header)
+		}
+	}
+}
+
+func TestTaskWritesIfMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       any
+		headers    []string
+		require    bool
+		wantStatus int
+	}{
+		{"put current version", http.MethodPut, map[string]string{"title": "New"}, []string{"If-Match", `"1"`}, false, http.StatusOK},
+		{"put stale version", http.MethodPut, map[string]string{"title": "New"}, []string{"If-Match", `"2"`}, false, http.StatusPreconditionFailed},
+		{"put weak tag", http.MethodPut, map[string]string{"title": "New"}, []string{"If-Match", `W/"1"`}, false, http.StatusPreconditionFailed},
+		{"put without If-Match", http.MethodPut, map[string]string{"title": "New"}, nil, false, http.StatusOK},
+		{"put without If-Match when required", http.MethodPut, map[string]string{"title": "New"}, nil, true, http.StatusPreconditionRequired},
+		{"patch stale version", http.MethodPatch, `{"title": "New"}`, []string{"If-Match", `"2"`, "Content-Type", mediaTypeMergePatch}, false, http.StatusPreconditionFailed},
+		{"patch without If-Match when required", http.MethodPatch, `{"title": "New"}`, []string{"Content-Type", mediaTypeMergePatch}, true, http.StatusPreconditionRequired},
+		{"delete current version", http.MethodDelete, nil, []string{"If-Match", `"1"`}, false, http.StatusOK},
+		{"delete stale version", http.MethodDelete, nil, []string{"If-Match", `"2"`}, false, http.StatusPreconditionFailed},
+		{"delete without If-Match when required", http.MethodDelete, nil, nil, true, http.StatusPreconditionRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			user := createTestUser(t, "writer")
+			createTestTask(t, user, "Old")
+
+			previous := requireIfMatch
+			requireIfMatch = tt.require
+			t.Cleanup(func() { requireIfMatch = previous })
+
+			w := doJSON(taskRouter(user), tt.method, "/tasks/1", tt.body, tt.headers...)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", w.Code, w.Body, tt.wantStatus)
+			}
+
+			var task model.Task
+			found := database.DB.Where("user_id = ? AND task_id = 1", user.ID).Limit(1).Find(&task).RowsAffected == 1
+			switch {
+			case tt.wantStatus == http.StatusOK && tt.method == http.MethodDelete:
+				if found {
+					t.Error("task wasn't deleted")
+				}
+			case tt.wantStatus == http.StatusOK:
+				if task.Version != 2 || w.Header().Get("ETag") != `"2"` {
+					t.Errorf("version = %d, ETag = %q; want 2", task.Version, w.Header().Get("ETag"))
+				}
+			default:
+				if !found || task.Version != 1 || task.Title != "Old" {
+					t.Errorf("rejected write changed the task: %+v", task)
+				}
+			}
+			if tt.wantStatus == http.StatusPreconditionFailed && w.Header().Get("ETag") != `"1"` {
+				t.Errorf("412 with ETag %q, want the current \"1\"", w.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestTaskWritesRacingAnotherClient(t *testing.T) {
+	const bump = "UPDATE tasks SET version = version + 1, title = 'Theirs'"
+	const remove = "DELETE FROM tasks"
+
+	tests := []struct {
+		name       string
+		method     string
+		body       any
+		headers    []string
+		write      string
+		race       string
+		wantStatus int
+		wantTitle  string // Title of the task afterwards, "" if it's gone
+	}{
+		{"put loses to an update", http.MethodPut, map[string]string{"title": "Mine"}, nil, "update", bump, http.StatusPreconditionFailed, "Theirs"},
+		{"patch loses to an update", http.MethodPatch, `{"title": "Mine"}`, []string{"Content-Type", mediaTypeMergePatch}, "update", bump, http.StatusPreconditionFailed, "Theirs"},
+		{"put after a delete", http.MethodPut, map[string]string{"title": "Mine"}, nil, "update", remove, http.StatusNotFound, ""},
+		{"patch after a delete", http.MethodPatch, `{"title": "Mine"}`, []string{"Content-Type", mediaTypeMergePatch}, "update", remove, http.StatusNotFound, ""},
+		{"delete without If-Match ignores the version", http.MethodDelete, nil, nil, "delete", bump, http.StatusOK, ""},
+		{"delete with If-Match loses to an update", http.MethodDelete, nil, []string{"If-Match", `"1"`}, "delete", bump, http.StatusPreconditionFailed, "Theirs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			user := createTestUser(t, "racer")
+			createTestTask(t, user, "Old")
+			raceBefore(t, tt.write, tt.race)
+
+			w := doJSON(taskRouter(user), tt.method, "/tasks/1", tt.body, tt.headers...)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", w.Code, w.Body, tt.wantStatus)
+			}
+
+			var task model.Task
+			database.DB.Where("user_id = ? AND task_id = 1", user.ID).Limit(1).Find(&task)
+			if task.Title != tt.wantTitle {
+				t.Errorf("title afterwards = %q, want %q", task.Title, tt.wantTitle)
+			}
+
+			// ✅ A 412 carries the task as it is now, so the client can redo its change
+			if tt.wantStatus == http.StatusPreconditionFailed {
+				var body struct {
+					Task model.Task `json:"task"`
+				}
+				json.Unmarshal(w.Body.Bytes(), &body)
+				if body.Task.Version != 2 || body.Task.Title != "Theirs" || w.Header().Get("ETag") != `"2"` {
+					t.Errorf("412 body task = %+v, ETag %q; want version 2", body.Task, w.Header().Get("ETag"))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+// exportFlushEvery is how many tasks are written between flushes to the client
+const exportFlushEvery = 200
+
+// Content types and file extensions of the export formats
+var exportFormats = map[string]struct{ contentType, ext string }{
+	"csv":  {"text/csv; charset=utf-8", "csv"},
+	"json": {"application/json; charset=utf-8", "json"},
+	"md":   {"text/markdown; charset=utf-8", "md"},
+	"ics":  {"text/calendar; charset=utf-8", "ics"},
+}
+
+// ✅ Export Tasks (?format=csv|json|md|ics, plus the task list filters)
+//
+// Tasks are read from a cursor and written as they come, so exports of any
+// size use constant memory.
+func ExportTasks(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	spec, ok := exportFormats[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, json, md or ics"})
+		return
+	}
+
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := applyTaskFilter(database.DB.Model(&model.Task{}).Where("user_id = ?", c.GetUint("user_id")), filter).Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export tasks"})
+		return
+	}
+	defer rows.Close()
+
+	// ✅ From here on the status is sent, errors can only cut the download short
+	filename := fmt.Sprintf("tasks-%s.%s", time.Now().Format("2006-01-02"), spec.ext)
+	c.Header("Content-Type", spec.contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	var writeErr error
+	switch format {
+	case "csv":
+		writeErr = exportCSV(c, rows)
+	case "json":
+		writeErr = exportJSON(c, rows)
+	case "md":
+		writeErr = exportMarkdown(c, rows)
+	case "ics":
+		writeErr = exportICS(c, rows)
+	}
+	if writeErr != nil {
+		log.Println("❌ Task export interrupted:", writeErr)
+	}
+}
+
+// eachExportedTask scans the rows one task at a time, flushing to the client periodically
+func eachExportedTask(c *gin.Context, rows *sql.Rows, flush func() error, fn func(model.Task) error) error {
+	count := 0
+	for rows.Next() {
+		var task model.Task
+		if err := database.DB.ScanRows(rows, &task); err != nil {
+			return err
+		}
+		if err := fn(task); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+	}
+	return rows.Err()
+}
+
+func exportCSV(c *gin.Context, rows *sql.Rows) error {
+	w := csv.NewWriter(c.Writer)
+	flush := func() error {
+		w.Flush()
+		return w.Error()
+	}
+
+	w.Write([]string{"id", "title", "description", "status", "due_date", "created_at", "updated_at"})
+	err := eachExportedTask(c, rows, flush, func(task model.Task) error {
+		due := ""
+		if task.DueDate != nil {
+			due = task.DueDate.Format(time.RFC3339)
+		}
+		return w.Write([]string{
+			strconv.FormatUint(uint64(task.TaskID), 10),
+			csvText(task.Title),
+			csvText(task.Description),
+			csvText(task.Status),
+			due,
+			task.CreatedAt.Format(time.RFC3339),
+			task.UpdatedAt.Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// csvFormulaStart are the first characters that make spreadsheet apps treat a cell as a formula
+const csvFormulaStart = "=+-@\t\r"
+
+// csvText stops spreadsheet apps from running a user-written cell as a
+// formula (CSV injection) by prefixing it with a quote, as OWASP suggests
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune(csvFormulaStart, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// csvUntext undoes csvText, so exported CSVs import unchanged
+func csvUntext(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(csvFormulaStart, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+// exportJSON writes the same array GetTasks returns, one element at a time
+func exportJSON(c *gin.Context, rows *sql.Rows) error {
+	w := bufio.NewWriter(c.Writer)
+	encoder := json.NewEncoder(w)
+
+	first := true
+	w.WriteString("[")
+	err := eachExportedTask(c, rows, w.Flush, func(task model.Task) error {
+		if !first {
+			w.WriteString(",")
+		}
+		first = false
+		return encoder.Encode(task)
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteString("]\n")
+	return w.Flush()
+}
+
+// exportMarkdown writes a checklist, completed tasks checked off
+func exportMarkdown(c *gin.Context, rows *sql.Rows) error {
+	w := bufio.NewWriter(c.Writer)
+	w.WriteString("# Tasks\n\n")
+
+	err := eachExportedTask(c, rows, w.Flush, func(task model.Task) error {
+		check := " "
+		if task.Status == model.StatusCompleted {
+			check = "x"
+		}
+		fmt.Fprintf(w, "- [%s] **%s** (#%d, %s", check, markdownInline(task.Title), task.TaskID, markdownInline(task.Status))
+		if task.DueDate != nil {
+			fmt.Fprintf(w, ", due %s", task.DueDate.Format("2006-01-02 15:04 MST"))
+		}
+		w.WriteString(")\n")
+
+		// Indented under the item so multi-line descriptions stay part of it
+		if description := strings.TrimSpace(task.Description); description != "" {
+			for _, line := range strings.Split(description, "\n") {
+				w.WriteString("  " + strings.TrimRight(line, "\r") + "\n")
+			}
+		}
+		_, err := w.WriteString("\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// exportICS writes a calendar with a VTODO per task
+func exportICS(c *gin.Context, rows *sql.Rows) error {
+	ics := newICSWriter(c.Writer, "Tasks")
+	err := eachExportedTask(c, rows, ics.w.Flush, func(task model.Task) error {
+		ics.WriteTodo(task)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ics.Close()
+}
+
+// markdownInline keeps a value on one line and stops it from adding formatting
+func markdownInline(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`).Replace(s)
+}
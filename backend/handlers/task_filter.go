@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+)
+
+// Sortable task fields mapped to their columns
+var taskSortColumns = map[string]string{
+	"id":         "task_id",
+	"title":      "title",
+	"status":     "status",
+	"due_date":   "due_date",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// validateTaskFilter rejects filters that can't be turned into a query
+func validateTaskFilter(filter model.TaskFilter) error {
+	if filter.Sort != "" {
+		if _, ok := taskSortColumns[strings.TrimPrefix(filter.Sort, "-")]; !ok {
+			return fmt.Errorf("cannot sort by %q", filter.Sort)
+		}
+	}
+	if filter.DueAfter != nil && filter.DueBefore != nil && filter.DueAfter.After(*filter.DueBefore) {
+		return fmt.Errorf("due_after must be before due_before")
+	}
+	return nil
+}
+
+// applyTaskFilter adds the filter's conditions and ordering to a task query
+func applyTaskFilter(query *gorm.DB, filter model.TaskFilter) *gorm.DB {
+	if len(filter.Status) > 0 {
+		query = query.Where("status IN ?", filter.Status)
+	}
+	if filter.DueAfter != nil {
+		query = query.Where("due_date >= ?", *filter.DueAfter)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("due_date <= ?", *filter.DueBefore)
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		query = query.Where("(title ILIKE ? OR description ILIKE ?)", pattern, pattern)
+	}
+
+	column, direction := "task_id", "ASC"
+	if filter.Sort != "" {
+		column = taskSortColumns[strings.TrimPrefix(filter.Sort, "-")]
+		if strings.HasPrefix(filter.Sort, "-") {
+			direction = "DESC"
+		}
+	}
+	// ✅ task_id breaks ties so the order is stable
+	return query.Order(column + " " + direction).Order("task_id")
+}
+
+// taskFilterFromQuery reads ?status=a,b&due_after=&due_before=&q=&sort= into a filter
+func taskFilterFromQuery(c *gin.Context) (model.TaskFilter, error) {
+	filter := model.TaskFilter{
+		Query: c.Query("q"),
+		Sort:  c.Query("sort"),
+	}
+
+	if status := c.Query("status"); status != "" {
+		filter.Status = strings.Split(status, ",")
+	}
+	for param, target := range map[string]**time.Time{"due_after": &filter.DueAfter, "due_before": &filter.DueBefore} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+		}
+		*target = &t
+	}
+
+	return filter, validateTaskFilter(filter)
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,622 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxImportSize      = 5 << 20 // 5 MB
+	maxImportRows      = 5000
+	maxStatusLength    = 50
+	importPreviewLimit = 20
+	importBatchSize    = 500
+)
+
+// Formats accepted by POST /tasks/import
+const (
+	importFormatCSV     = "csv"
+	importFormatJSON    = "json"    // Our own format: [{title, description, status, due_date}]
+	importFormatTrello  = "trello"  // Trello board export (JSON)
+	importFormatTodoist = "todoist" // Todoist CSV template or API/sync JSON
+)
+
+// importFields are the task fields an import can set
+var importFields = []string{"title", "description", "status", "due_date"}
+
+// importMapping customizes how source records become tasks
+type importMapping struct {
+	Columns map[string]string `json:"columns"` // Task field -> source column/key ("" to ignore the field)
+	Status  map[string]string `json:"status"`  // Source status (or Trello list) -> task status
+}
+
+// importRecord is one source record, keyed by task field, before validation
+type importRecord struct {
+	Row    int
+	Fields map[string]string
+}
+
+// importError is a row-level validation problem
+type importError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+// ✅ Import Tasks (CSV, JSON, Trello or Todoist)
+//
+// Send the file as multipart field "file" or as the raw body. Options, as
+// query or form fields: format (guessed if omitted), mapping (JSON
+// importMapping), dry_run=true to only validate, skip_invalid=true to import
+// the valid rows even if others fail.
+func ImportTasks(c *gin.Context) {
+	user := c.MustGet("user").(model.User)
+
+	data, filename, err := readImportUpload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := importOption(c, "format")
+	if format == "" {
+		format = detectImportFormat(filename, data)
+	}
+
+	var mapping importMapping
+	if raw := importOption(c, "mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapping: " + err.Error()})
+			return
+		}
+		for field := range mapping.Columns {
+			if !containsString(importFields, field) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown field %q in mapping, use one of %s", field, strings.Join(importFields, ", "))})
+				return
+			}
+		}
+	}
+	dryRun := importOption(c, "dry_run") == "true"
+	skipInvalid := importOption(c, "skip_invalid") == "true"
+
+	records, err := parseImport(format, data, mapping)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(records) > maxImportRows {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Imports are limited to %d tasks", maxImportRows)})
+		return
+	}
+
+	// ✅ Dates without a zone are in the user's own time zone
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	tasks := make([]model.Task, 0, len(records))
+	importErrors := []importError{}
+	for _, record := range records {
+		task, errs := importedTask(record, mapping, location)
+		if len(errs) > 0 {
+			importErrors = append(importErrors, errs...)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	invalid := len(records) - len(tasks)
+	summary := gin.H{
+		"format":  format,
+		"dry_run": dryRun,
+		"total":   len(records),
+		"valid":   len(tasks),
+		"invalid": invalid,
+		"errors":  importErrors,
+	}
+
+	if dryRun {
+		summary["preview"] = tasks[:min(len(tasks), importPreviewLimit)]
+		c.JSON(http.StatusOK, summary)
+		return
+	}
+	if invalid > 0 && !skipInvalid {
+		summary["error"] = "Some rows are invalid, fix them or pass skip_invalid=true"
+		c.JSON(http.StatusUnprocessableEntity, summary)
+		return
+	}
+	if len(tasks) == 0 {
+		summary["error"] = "Nothing to import"
+		c.JSON(http.StatusBadRequest, summary)
+		return
+	}
+
+	// ✅ All or nothing, with IDs continuing the user's sequence like CreateTask
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		next, err := nextTaskID(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		watchers := make([]model.TaskWatcher, len(tasks))
+		for i := range tasks {
+			tasks[i].UserID = user.ID
+			tasks[i].TaskID = next + uint(i)
+			watchers[i] = model.TaskWatcher{OwnerID: user.ID, TaskID: tasks[i].TaskID, UserID: user.ID, Reason: services.WatchReasonCreator}
+		}
+
+		if err := tx.CreateInBatches(&tasks, importBatchSize).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&watchers, importBatchSize).Error
+	})
+	if err != nil {
+		log.Println("❌ Failed to import tasks:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import tasks"})
+		return
+	}
+
+	for _, task := range tasks {
+		services.EmitEvent(task.UserID, services.WebhookTaskCreated, task)
+	}
+
+	summary["imported"] = len(tasks)
+	summary["first_task_id"] = tasks[0].TaskID
+	summary["last_task_id"] = tasks[len(tasks)-1].TaskID
+	c.JSON(http.StatusCreated, summary)
+}
+
+// readImportUpload returns the uploaded file from the "file" form field, or the raw body
+func readImportUpload(c *gin.Context) ([]byte, string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("No file provided, send it as the \"file\" form field")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", errors.New("Failed to open file")
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		return data, header.Filename, err
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("Import files are limited to %d MB", maxImportSize>>20)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, "", errors.New("No file provided")
+	}
+	return data, "", nil
+}
+
+// importOption reads an option from the query string, falling back to the form
+func importOption(c *gin.Context, name string) string {
+	if value := c.Query(name); value != "" {
+		return value
+	}
+	return c.PostForm(name)
+}
+
+// detectImportFormat guesses the format from the file name and content
+func detectImportFormat(filename string, data []byte) string {
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return importFormatCSV
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return importFormatCSV
+	}
+
+	// Trello boards have "cards" and "lists", Todoist sync exports have "items"
+	var probe map[string]json.RawMessage
+	if json.Unmarshal(trimmed, &probe) == nil {
+		if _, ok := probe["cards"]; ok {
+			return importFormatTrello
+		}
+		if _, ok := probe["items"]; ok {
+			return importFormatTodoist
+		}
+	}
+	return importFormatJSON
+}
+
+// parseImport turns the upload into records keyed by task field
+func parseImport(format string, data []byte, mapping importMapping) ([]importRecord, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Excel's UTF-8 BOM
+
+	switch format {
+	case importFormatCSV:
+		return parseCSVImport(data, mapping)
+	case importFormatJSON:
+		return parseJSONImport(data, mapping)
+	case importFormatTrello:
+		return parseTrelloImport(data, mapping)
+	case importFormatTodoist:
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			return parseTodoistJSONImport(trimmed, mapping)
+		}
+		return parseTodoistCSVImport(data, mapping)
+	}
+	return nil, fmt.Errorf("Unknown format %q, use csv, json, trello or todoist", format)
+}
+
+// sourceName is the column/key a task field is read from (and whether it's read at all)
+func (m importMapping) sourceName(field string) (string, bool) {
+	if source, ok := m.Columns[field]; ok {
+		return source, source != ""
+	}
+	return field, true
+}
+
+// applyColumns lets mapping.Columns override the fields read from a JSON
+// object by their usual keys: a field is read from the named key instead, or
+// dropped when mapped to ""
+func (m importMapping) applyColumns(fields map[string]string, item map[string]any) {
+	for field, source := range m.Columns {
+		if source == "" {
+			delete(fields, field)
+			continue
+		}
+		fields[field] = jsonString(item[source])
+	}
+}
+
+// mapStatus applies the status mapping, matching case-insensitively
+func (m importMapping) mapStatus(status string) (string, bool) {
+	if mapped, ok := m.Status[status]; ok {
+		return mapped, true
+	}
+	for source, mapped := range m.Status {
+		if strings.EqualFold(source, status) {
+			return mapped, true
+		}
+	}
+	return status, false
+}
+
+// parseCSVImport reads a CSV with a header row. Columns are matched to task
+// fields by name (case-insensitive), or as configured in mapping.Columns.
+func parseCSVImport(data []byte, mapping importMapping) ([]importRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("Could not read the CSV header row")
+	}
+
+	columns := map[string]int{}
+	for _, field := range importFields {
+		source, ok := mapping.sourceName(field)
+		if !ok {
+			continue
+		}
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), source) {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("No title column found, name it \"title\" or set mapping.columns.title")
+	}
+
+	return readCSVRecords(reader, columns, nil)
+}
+
+// readCSVRecords reads the remaining rows, skipping empty ones and any keep rejects
+func readCSVRecords(reader *csv.Reader, columns map[string]int, keep func([]string) bool) ([]importRecord, error) {
+	var records []importRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CSV: %v", err)
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" || (keep != nil && !keep(row)) {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := importRecord{Row: line, Fields: map[string]string{}}
+		for field, i := range columns {
+			if i < len(row) {
+				record.Fields[field] = csvUntext(row[i])
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+// parseJSONImport reads our own format: an array of task objects, or {"tasks": [...]}
+func parseJSONImport(data []byte, mapping importMapping) ([]importRecord, error) {
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		var wrapped struct {
+			Tasks []map[string]any `json:"tasks"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil || wrapped.Tasks == nil {
+			return nil, errors.New("Invalid JSON, expected an array of tasks or {\"tasks\": [...]}")
+		}
+		items = wrapped.Tasks
+	}
+
+	records := make([]importRecord, 0, len(items))
+	for i, item := range items {
+		record := importRecord{Row: i + 1, Fields: map[string]string{}}
+		for _, field := range importFields {
+			if source, ok := mapping.sourceName(field); ok {
+				record.Fields[field] = jsonString(item[source])
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// parseTrelloImport reads a Trello board export. Cards marked complete become
+// "completed"; otherwise the card's list name goes through mapping.Status and
+// falls back to "pending". mapping.Columns can read fields from other card keys.
+func parseTrelloImport(data []byte, mapping importMapping) ([]importRecord, error) {
+	var board struct {
+		Lists []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"lists"`
+		Cards []struct {
+			Name        string  `json:"name"`
+			Desc        string  `json:"desc"`
+			Due         *string `json:"due"`
+			DueComplete bool    `json:"dueComplete"`
+			Closed      bool    `json:"closed"`
+			IDList      string  `json:"idList"`
+		} `json:"cards"`
+	}
+	var raw struct {
+		Cards []map[string]any `json:"cards"`
+	}
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, errors.New("Invalid Trello export: " + err.Error())
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.New("Invalid Trello export: " + err.Error())
+	}
+
+	lists := map[string]string{}
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+	}
+
+	records := make([]importRecord, 0, len(board.Cards))
+	for i, card := range board.Cards {
+		if card.Closed {
+			continue // Archived cards
+		}
+
+		status, ok := mapping.mapStatus(lists[card.IDList])
+		if !ok {
+			status = "pending"
+		}
+		if card.DueComplete {
+			status = model.StatusCompleted
+		}
+
+		due := ""
+		if card.Due != nil {
+			due = *card.Due
+		}
+		fields := map[string]string{
+			"title":       card.Name,
+			"description": card.Desc,
+			"status":      status,
+			"due_date":    due,
+		}
+		mapping.applyColumns(fields, raw.Cards[i])
+		records = append(records, importRecord{Row: i + 1, Fields: fields})
+	}
+	return records, nil
+}
+
+// parseTodoistJSONImport reads Todoist tasks from the REST API (an array) or a
+// sync export ({"items": [...]}). mapping.Columns can read fields from other task keys.
+func parseTodoistJSONImport(data []byte, mapping importMapping) ([]importRecord, error) {
+	type todoistTask struct {
+		Content     string `json:"content"`
+		Description string `json:"description"`
+		IsCompleted bool   `json:"is_completed"`
+		Checked     bool   `json:"checked"`
+		Due         *struct {
+			Date     string `json:"date"`
+			Datetime string `json:"datetime"`
+		} `json:"due"`
+	}
+
+	var items []todoistTask
+	var raw []map[string]any
+	if err := json.Unmarshal(data, &items); err == nil {
+		json.Unmarshal(data, &raw)
+	} else {
+		var sync struct {
+			Items []todoistTask `json:"items"`
+		}
+		if err := json.Unmarshal(data, &sync); err != nil {
+			return nil, errors.New("Invalid Todoist export: " + err.Error())
+		}
+		items = sync.Items
+
+		var rawSync struct {
+			Items []map[string]any `json:"items"`
+		}
+		json.Unmarshal(data, &rawSync)
+		raw = rawSync.Items
+	}
+
+	records := make([]importRecord, 0, len(items))
+	for i, item := range items {
+		status := "pending"
+		if item.IsCompleted || item.Checked {
+			status = model.StatusCompleted
+		}
+
+		due := ""
+		if item.Due != nil {
+			due = item.Due.Datetime
+			if due == "" {
+				due = item.Due.Date
+			}
+		}
+
+		fields := map[string]string{
+			"title":       item.Content,
+			"description": item.Description,
+			"status":      status,
+			"due_date":    due,
+		}
+		mapping.applyColumns(fields, raw[i])
+		records = append(records, importRecord{Row: i + 1, Fields: fields})
+	}
+	return records, nil
+}
+
+// parseTodoistCSVImport reads Todoist's CSV project template (TYPE, CONTENT,
+// DESCRIPTION, DATE, ...), keeping only the "task" rows. mapping.Columns can
+// read fields from other columns. Todoist dates can be free text like "every
+// monday"; map due_date to "" to ignore them.
+func parseTodoistCSVImport(data []byte, mapping importMapping) ([]importRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("Could not read the CSV header row")
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	typeColumn, hasType := index["TYPE"]
+	if _, ok := index["CONTENT"]; !ok || !hasType {
+		return nil, errors.New("Not a Todoist CSV export (missing TYPE or CONTENT column)")
+	}
+
+	sources := map[string]string{"title": "CONTENT", "description": "DESCRIPTION", "due_date": "DATE"}
+	for field, source := range mapping.Columns {
+		sources[field] = strings.ToUpper(strings.TrimSpace(source))
+	}
+	columns := map[string]int{}
+	for field, source := range sources {
+		if i, ok := index[source]; ok && source != "" {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("No title column found, set mapping.columns.title to a column of the export")
+	}
+
+	return readCSVRecords(reader, columns, func(row []string) bool {
+		return typeColumn < len(row) && strings.EqualFold(row[typeColumn], "task")
+	})
+}
+
+// importedTask validates a record and converts it to a task (without IDs)
+func importedTask(record importRecord, mapping importMapping, location *time.Location) (model.Task, []importError) {
+	var errs []importError
+	fail := func(field, message string) {
+		errs = append(errs, importError{Row: record.Row, Field: field, Error: message})
+	}
+
+	task := model.Task{
+		Title:       strings.TrimSpace(record.Fields["title"]),
+		Description: strings.TrimSpace(record.Fields["description"]),
+		Status:      "pending",
+	}
+	if task.Title == "" {
+		fail("title", "title is required")
+	}
+
+	if status := strings.TrimSpace(record.Fields["status"]); status != "" {
+		task.Status, _ = mapping.mapStatus(status)
+		if len(task.Status) > maxStatusLength {
+			fail("status", fmt.Sprintf("status must be at most %d characters", maxStatusLength))
+		}
+	}
+
+	if due := strings.TrimSpace(record.Fields["due_date"]); due != "" {
+		parsed, err := parseImportDate(due, location)
+		if err != nil {
+			fail("due_date", fmt.Sprintf("unrecognized date %q, use YYYY-MM-DD or RFC 3339", due))
+		} else {
+			task.DueDate = &parsed
+		}
+	}
+
+	return task, errs
+}
+
+// parseImportDate accepts RFC 3339 timestamps and common zone-less layouts
+func parseImportDate(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	var lastErr error
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		t, err := time.ParseInLocation(layout, value, location)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
+
+// jsonString flattens a decoded JSON value into a string field
+func jsonString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+func TestParseImport(t *testing.T) {
+	trello := `{
+		"lists": [{"id": "l1", "name": "Doing"}, {"id": "l2", "name": "Backlog"}],
+		"cards": [
+			{"name": "Card", "desc": "Details", "due": "2026-11-01T09:00:00.000Z", "idList": "l1", "url": "https://trello.com/c/1"},
+			{"name": "Archived", "closed": true, "idList": "l1"},
+			{"name": "Done", "dueComplete": true, "idList": "l2"}
+		]
+	}`
+	todoistREST := `[
+		{"content": "Buy milk", "description": "2%", "checked": false, "priority": 4, "due": {"date": "2026-11-02"}},
+		{"content": "Call mom", "is_completed": true, "due": {"date": "2026-11-03", "datetime": "2026-11-03T18:00:00Z"}}
+	]`
+	todoistSync := `{"items": [{"content": "Synced", "project_id": "inbox", "checked": true}]}`
+	todoistCSV := "TYPE,CONTENT,DESCRIPTION,PRIORITY,DATE\n" +
+		"section,Header,,,\n" +
+		"task,Write tests,All of them,1,every monday\n"
+
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		mapping importMapping
+		want    []map[string]string // Fields of every record, in order
+	}{
+		{"csv by header name", importFormatCSV, "Title,Status,Due_Date\nShip it,done,2026-11-01\n", importMapping{},
+			[]map[string]string{{"title": "Ship it", "status": "done", "due_date": "2026-11-01"}}},
+		{"csv mapped columns", importFormatCSV, "Name,Notes,When\nShip it,soon,2026-11-01\n",
+			importMapping{Columns: map[string]string{"title": "name", "description": "Notes", "due_date": ""}},
+			[]map[string]string{{"title": "Ship it", "description": "soon"}}},
+		{"csv undoes formula escaping", importFormatCSV, "title\n'=1+1\n", importMapping{},
+			[]map[string]string{{"title": "=1+1"}}},
+		{"csv skips blank rows", importFormatCSV, "title\n\n,\nOne\n", importMapping{},
+			[]map[string]string{{"title": "One"}}},
+		{"json", importFormatJSON, `{"tasks": [{"title": "One", "status": "done", "extra": 1}]}`, importMapping{},
+			[]map[string]string{{"title": "One", "description": "", "status": "done", "due_date": ""}}},
+		{"json mapped keys", importFormatJSON, `[{"name": "One", "priority": 3}]`,
+			importMapping{Columns: map[string]string{"title": "name", "description": "priority", "status": "", "due_date": ""}},
+			[]map[string]string{{"title": "One", "description": "3"}}},
+		{"trello", importFormatTrello, trello, importMapping{Status: map[string]string{"doing": "in_progress"}},
+			[]map[string]string{
+				{"title": "Card", "description": "Details", "status": "in_progress", "due_date": "2026-11-01T09:00:00.000Z"},
+				{"title": "Done", "description": "", "status": model.StatusCompleted, "due_date": ""},
+			}},
+		{"trello mapped keys", importFormatTrello, trello, importMapping{Columns: map[string]string{"description": "url", "due_date": ""}},
+			[]map[string]string{
+				{"title": "Card", "description": "https://trello.com/c/1", "status": "pending"},
+				{"title": "Done", "description": "", "status": model.StatusCompleted},
+			}},
+		{"todoist rest", importFormatTodoist, todoistREST, importMapping{},
+			[]map[string]string{
+				{"title": "Buy milk", "description": "2%", "status": "pending", "due_date": "2026-11-02"},
+				{"title": "Call mom", "description": "", "status": model.StatusCompleted, "due_date": "2026-11-03T18:00:00Z"},
+			}},
+		{"todoist rest mapped keys", importFormatTodoist, todoistREST, importMapping{Columns: map[string]string{"description": "priority", "due_date": ""}},
+			[]map[string]string{
+				{"title": "Buy milk", "description": "4", "status": "pending"},
+				{"title": "Call mom", "description": "", "status": model.StatusCompleted},
+			}},
+		{"todoist sync mapped keys", importFormatTodoist, todoistSync, importMapping{Columns: map[string]string{"description": "project_id"}},
+			[]map[string]string{{"title": "Synced", "description": "inbox", "status": model.StatusCompleted, "due_date": ""}}},
+		{"todoist csv", importFormatTodoist, todoistCSV, importMapping{},
+			[]map[string]string{{"title": "Write tests", "description": "All of them", "due_date": "every monday"}}},
+		{"todoist csv mapped columns", importFormatTodoist, todoistCSV, importMapping{Columns: map[string]string{"description": "priority", "due_date": ""}},
+			[]map[string]string{{"title": "Write tests", "description": "1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := parseImport(tt.format, []byte(tt.data), tt.mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]map[string]string, len(records))
+			for i, record := range records {
+				got[i] = record.Fields
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("fields = %v\nwant     %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		mapping importMapping
+	}{
+		{"unknown format", "xml", "<tasks/>", importMapping{}},
+		{"csv without a title column", importFormatCSV, "name\nOne\n", importMapping{}},
+		{"json that isn't a task list", importFormatJSON, `{"cards": 1}`, importMapping{}},
+		{"broken trello export", importFormatTrello, `{"cards": "nope"}`, importMapping{}},
+		{"todoist csv without TYPE", importFormatTodoist, "CONTENT\nOne\n", importMapping{}},
+		{"todoist csv with title mapped away", importFormatTodoist, "TYPE,CONTENT\ntask,One\n", importMapping{Columns: map[string]string{"title": ""}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := parseImport(tt.format, []byte(tt.data), tt.mapping); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+func TestImportDryRunReportsErrors(t *testing.T) {
+	setupTestDB(t)
+	user := createTestUser(t, "importer")
+	r := routerAs(user)
+	r.POST("/tasks/import", ImportTasks)
+
+	csv := "title,due_date\nGood,2026-11-01\n,2026-11-02\nBad date,next week\n"
+	w := doJSON(r, http.MethodPost, "/tasks/import?format=csv&dry_run=true", csv, "Content-Type", "text/csv")
+	if w.Code != http.StatusOK {
+		t.Fatalf("dry run = %d %s, want 200", w.Code, w.Body)
+	}
+
+	var summary struct {
+		Total   int           `json:"total"`
+		Valid   int           `json:"valid"`
+		Invalid int           `json:"invalid"`
+		Errors  []importError `json:"errors"`
+		Preview []model.Task  `json:"preview"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &summary)
+	wantErrors := []importError{
+		{Row: 3, Field: "title", Error: "title is required"},
+		{Row: 4, Field: "due_date", Error: `unrecognized date "next week", use YYYY-MM-DD or RFC 3339`},
+	}
+	if summary.Total != 3 || summary.Valid != 1 || summary.Invalid != 2 || !reflect.DeepEqual(summary.Errors, wantErrors) {
+		t.Fatalf("summary = %+v, want 3 rows with errors %+v", summary, wantErrors)
+	}
+	if len(summary.Preview) != 1 || summary.Preview[0].Title != "Good" {
+		t.Fatalf("preview = %+v, want the valid row", summary.Preview)
+	}
+
+	var tasks int64
+	database.DB.Model(&model.Task{}).Count(&tasks)
+	if tasks != 0 {
+		t.Fatalf("dry run created %d tasks", tasks)
+	}
+
+	// ✅ The same file without dry_run is refused as a whole
+	w = doJSON(r, http.MethodPost, "/tasks/import?format=csv", csv, "Content-Type", "text/csv")
+	database.DB.Model(&model.Task{}).Count(&tasks)
+	if w.Code != http.StatusUnprocessableEntity || tasks != 0 {
+		t.Fatalf("import with invalid rows = %d and %d tasks, want 422 and none", w.Code, tasks)
+	}
+}
+
+func TestImportContinuesTaskIDs(t *testing.T) {
+	setupTestDB(t)
+	user := createTestUser(t, "importer")
+	other := createTestUser(t, "other")
+	for _, title := range []string{"One", "Two", "Three"} {
+		createTestTask(t, user, title)
+	}
+	database.DB.Where("user_id = ? AND task_id = 2", user.ID).Delete(&model.Task{}) // A gap doesn't get reused
+	for _, title := range []string{"A", "B", "C", "D", "E"} {
+		createTestTask(t, other, title) // Other users' IDs don't matter
+	}
+
+	r := routerAs(user)
+	r.POST("/tasks/import", ImportTasks)
+
+	wantRanges := [][2]uint{{4, 5}, {6, 7}}
+	for _, want := range wantRanges {
+		w := doJSON(r, http.MethodPost, "/tasks/import?format=json", `[{"title": "x"}, {"title": "y"}]`)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("import = %d %s, want 201", w.Code, w.Body)
+		}
+		var summary struct {
+			First uint `json:"first_task_id"`
+			Last  uint `json:"last_task_id"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &summary)
+		if summary.First != want[0] || summary.Last != want[1] {
+			t.Fatalf("imported IDs %d-%d, want %d-%d", summary.First, summary.Last, want[0], want[1])
+		}
+	}
+
+	var ids []uint
+	database.DB.Model(&model.Task{}).Where("user_id = ?", user.ID).Order("task_id").Pluck("task_id", &ids)
+	if !reflect.DeepEqual(ids, []uint{1, 3, 4, 5, 6, 7}) {
+		t.Fatalf("task IDs = %v", ids)
+	}
+	var watchers int64
+	database.DB.Model(&model.TaskWatcher{}).Where("owner_id = ? AND user_id = ?", user.ID, user.ID).Count(&watchers)
+	if watchers != 4 {
+		t.Fatalf("creator watchers = %d, want one per imported task", watchers)
+	}
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+// Patch formats PATCH /tasks/:id accepts, by Content-Type
+const (
+	mediaTypeMergePatch = "application/merge-patch+json" // RFC 7396, also assumed for plain application/json
+	mediaTypeJSONPatch  = "application/json-patch+json"  // RFC 6902
+)
+
+// mutableTaskFields are the task fields clients may change, by JSON name.
+// The rest (id, user_id, version, created_at, updated_at) belong to the server.
+var mutableTaskFields = []string{"title", "description", "status", "due_date"}
+
+// errPatchTestFailed is a JSON Patch "test" operation that didn't match
+var errPatchTestFailed = errors.New("test operation failed")
+
+// jsonPatchOperation is one step of an RFC 6902 patch
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ✅ Partially Update a Task (JSON Merge Patch or JSON Patch)
+//
+// Only the fields the patch touches change: leaving a field out keeps it,
+// while null (merge patch) or "remove" (JSON patch) clears it. Changing a
+// server-owned field is rejected instead of silently applied.
+func PatchTask(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var task model.Task
+	if err := database.DB.Where("user_id = ? AND task_id = ?", userID, taskID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or does not belong to you"})
+		return
+	}
+
+	// ✅ Refuse patches made against an older version
+	if !checkIfMatch(c, task) {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	// ✅ Patch the task's JSON form, then work out what actually changed
+	original, err := taskDocument(task)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to patch task"})
+		return
+	}
+	patched, _ := taskDocument(task)
+
+	switch c.ContentType() {
+	case mediaTypeMergePatch, "application/json":
+		err = applyMergePatch(patched, body)
+	case mediaTypeJSONPatch:
+		err = applyJSONPatch(patched, body)
+	default:
+		c.Header("Accept-Patch", mediaTypeMergePatch+", "+mediaTypeJSONPatch)
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + mediaTypeMergePatch + " or " + mediaTypeJSONPatch})
+		return
+	}
+	if errors.Is(err, errPatchTestFailed) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates, err := taskUpdates(original, patched)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "mutable_fields": mutableTaskFields})
+		return
+	}
+	if len(updates) == 0 {
+		c.Header("ETag", taskETag(task))
+		c.JSON(http.StatusOK, task)
+		return
+	}
+
+	// ✅ A new due date deserves a new reminder
+	if dueDate, ok := updates["due_date"]; ok && !sameDueDate(task.DueDate, dueDate.(*time.Time)) {
+		updates["reminder_sent_at"] = nil
+	}
+
+	previous := task
+	saved, err := updateTaskVersion(task, updates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task", "details": err.Error()})
+		return
+	}
+	if !saved {
+		taskChanged(c, task)
+		return
+	}
+	database.DB.Where("user_id = ? AND task_id = ?", task.UserID, task.TaskID).First(&task)
+
+	notifyTaskUpdated(userID, previous, task)
+
+	c.Header("ETag", taskETag(task))
+	c.JSON(http.StatusOK, task)
+}
+
+// taskDocument returns the task as the generic JSON object clients see
+func taskDocument(task model.Task) (map[string]any, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	return doc, decodeJSONValue(data, &doc)
+}
+
+// decodeJSONValue decodes keeping numbers exact, so "test" compares IDs reliably
+func decodeJSONValue(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// applyMergePatch applies an RFC 7396 merge patch: null removes a member,
+// objects merge recursively, anything else replaces
+func applyMergePatch(doc map[string]any, body []byte) error {
+	var patch map[string]any
+	if err := decodeJSONValue(body, &patch); err != nil || patch == nil {
+		return errors.New("merge patch must be a JSON object")
+	}
+	mergeInto(doc, patch)
+	return nil
+}
+
+func mergeInto(doc, patch map[string]any) {
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(doc, key)
+		case map[string]any:
+			target, ok := doc[key].(map[string]any)
+			if !ok {
+				target = map[string]any{}
+			}
+			mergeInto(target, value)
+			doc[key] = target
+		default:
+			doc[key] = value
+		}
+	}
+}
+
+// applyJSONPatch applies RFC 6902 operations in order. Tasks are flat, so
+// paths can only name top-level fields.
+func applyJSONPatch(doc map[string]any, body []byte) error {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return errors.New("JSON patch must be an array of operations")
+	}
+
+	for i, operation := range operations {
+		if err := applyJSONPatchOperation(doc, operation); err != nil {
+			return fmt.Errorf("operation %d (%s %s): %w", i, operation.Op, operation.Path, err)
+		}
+	}
+	return nil
+}
+
+func applyJSONPatchOperation(doc map[string]any, operation jsonPatchOperation) error {
+	field, err := patchField(operation.Path)
+	if err != nil {
+		return err
+	}
+
+	var value any
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return errors.New("value is required")
+		}
+		if err := decodeJSONValue(operation.Value, &value); err != nil {
+			return errors.New("invalid value")
+		}
+	case "move", "copy":
+		from, err := patchField(operation.From)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if value, ok = doc[from]; !ok {
+			return fmt.Errorf("from path %q does not exist", operation.From)
+		}
+		if operation.Op == "move" {
+			delete(doc, from)
+		}
+	case "remove":
+	default:
+		return errors.New("unknown op, use add, remove, replace, move, copy or test")
+	}
+
+	current, exists := doc[field]
+	switch operation.Op {
+	case "remove", "replace", "test":
+		if !exists {
+			return fmt.Errorf("path %q does not exist", operation.Path)
+		}
+	}
+
+	switch operation.Op {
+	case "remove":
+		delete(doc, field)
+	case "test":
+		if !reflect.DeepEqual(current, value) {
+			return errPatchTestFailed
+		}
+	default:
+		doc[field] = value
+	}
+	return nil
+}
+
+// patchField turns a JSON pointer like "/due_date" into the field name
+func patchField(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") || strings.Count(pointer, "/") != 1 {
+		return "", fmt.Errorf("path %q must point at a top-level task field", pointer)
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer[1:]), nil
+}
+
+// taskUpdates compares the patched task with the original and returns the
+// column updates, rejecting changes to fields clients don't own
+func taskUpdates(original, patched map[string]any) (map[string]any, error) {
+	keys := make([]string, 0, len(original)+len(patched))
+	for key := range original {
+		keys = append(keys, key)
+	}
+	for key := range patched {
+		if _, ok := original[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	updates := map[string]any{}
+	for _, key := range keys {
+		before, hadBefore := original[key]
+		after, hasAfter := patched[key]
+		if hadBefore == hasAfter && reflect.DeepEqual(before, after) {
+			continue
+		}
+		if !hadBefore {
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+		if !slices.Contains(mutableTaskFields, key) {
+			return nil, fmt.Errorf("%q is read-only", key)
+		}
+
+		switch key {
+		case "title":
+			title, _ := after.(string)
+			if title = strings.TrimSpace(title); title == "" {
+				return nil, errors.New("title must be a non-empty string")
+			}
+			updates["title"] = title
+		case "description":
+			description, ok := after.(string)
+			if !ok && after != nil {
+				return nil, errors.New("description must be a string")
+			}
+			updates["description"] = description
+		case "status":
+			status, _ := after.(string)
+			if status = strings.TrimSpace(status); status == "" || len(status) > maxStatusLength {
+				return nil, fmt.Errorf("status must be a non-empty string of at most %d characters", maxStatusLength)
+			}
+			updates["status"] = status
+		case "due_date":
+			var dueDate *time.Time
+			if after != nil {
+				value, _ := after.(string)
+				t, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					return nil, errors.New("due_date must be an RFC 3339 timestamp or null")
+				}
+				dueDate = &t
+			}
+			updates["due_date"] = dueDate
+		}
+	}
+	return updates, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+func TestPatchTask(t *testing.T) {
+	due := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		check       func(t *testing.T, task model.Task)
+	}{
+		// RFC 7396 merge patch
+		{"merge null clears description", mediaTypeMergePatch, `{"description": null}`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.Description != "" || task.Title != "Write report" {
+				t.Errorf("description = %q, title = %q; want cleared and kept", task.Description, task.Title)
+			}
+		}},
+		{"merge null clears due_date", mediaTypeMergePatch, `{"due_date": null}`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.DueDate != nil {
+				t.Errorf("due_date = %v, want nil", task.DueDate)
+			}
+		}},
+		{"merge sets fields", mediaTypeMergePatch, `{"status": "done", "due_date": "2026-12-24T18:00:00Z"}`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.Status != "done" || task.DueDate == nil || !task.DueDate.Equal(time.Date(2026, 12, 24, 18, 0, 0, 0, time.UTC)) {
+				t.Errorf("status = %q, due_date = %v", task.Status, task.DueDate)
+			}
+		}},
+		{"plain JSON is a merge patch", "application/json", `{"title": "Renamed"}`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.Title != "Renamed" || task.Description != "First draft" {
+				t.Errorf("title = %q, description = %q", task.Title, task.Description)
+			}
+		}},
+		{"merge changing version", mediaTypeMergePatch, `{"version": 7}`, http.StatusUnprocessableEntity, nil},
+		{"merge changing user_id", mediaTypeMergePatch, `{"user_id": 99}`, http.StatusUnprocessableEntity, nil},
+		{"merge unknown field", mediaTypeMergePatch, `{"priority": "high"}`, http.StatusUnprocessableEntity, nil},
+		{"merge empty title", mediaTypeMergePatch, `{"title": "  "}`, http.StatusUnprocessableEntity, nil},
+		{"merge patch that isn't an object", mediaTypeMergePatch, `["title"]`, http.StatusBadRequest, nil},
+
+		// RFC 6902 JSON patch
+		{"add", mediaTypeJSONPatch, `[{"op": "add", "path": "/title", "value": "Added"}]`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.Title != "Added" {
+				t.Errorf("title = %q", task.Title)
+			}
+		}},
+		{"remove", mediaTypeJSONPatch, `[{"op": "remove", "path": "/description"}, {"op": "remove", "path": "/due_date"}]`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.Description != "" || task.DueDate != nil {
+				t.Errorf("description = %q, due_date = %v; want both cleared", task.Description, task.DueDate)
+			}
+		}},
+		{"replace", mediaTypeJSONPatch, `[{"op": "replace", "path": "/status", "value": "in_progress"}]`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.Status != "in_progress" {
+				t.Errorf("status = %q", task.Status)
+			}
+		}},
+		{"move", mediaTypeJSONPatch, `[{"op": "move", "from": "/description", "path": "/title"}]`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.Title != "First draft" || task.Description != "" {
+				t.Errorf("title = %q, description = %q", task.Title, task.Description)
+			}
+		}},
+		{"copy", mediaTypeJSONPatch, `[{"op": "copy", "from": "/title", "path": "/description"}]`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.Title != "Write report" || task.Description != "Write report" {
+				t.Errorf("title = %q, description = %q", task.Title, task.Description)
+			}
+		}},
+		{"test then replace", mediaTypeJSONPatch, `[{"op": "test", "path": "/status", "value": "pending"}, {"op": "replace", "path": "/status", "value": "done"}]`, http.StatusOK, func(t *testing.T, task model.Task) {
+			if task.Status != "done" {
+				t.Errorf("status = %q", task.Status)
+			}
+		}},
+		{"failed test", mediaTypeJSONPatch, `[{"op": "test", "path": "/status", "value": "done"}, {"op": "replace", "path": "/title", "value": "Never"}]`, http.StatusConflict, nil},
+		{"move into a read-only field", mediaTypeJSONPatch, `[{"op": "move", "from": "/description", "path": "/created_at"}]`, http.StatusUnprocessableEntity, nil},
+		{"copy into a read-only field", mediaTypeJSONPatch, `[{"op": "copy", "from": "/title", "path": "/user_id"}]`, http.StatusUnprocessableEntity, nil},
+		{"replace version", mediaTypeJSONPatch, `[{"op": "replace", "path": "/version", "value": 7}]`, http.StatusUnprocessableEntity, nil},
+		{"replace user_id", mediaTypeJSONPatch, `[{"op": "replace", "path": "/user_id", "value": 99}]`, http.StatusUnprocessableEntity, nil},
+		{"add unknown field", mediaTypeJSONPatch, `[{"op": "add", "path": "/priority", "value": "high"}]`, http.StatusUnprocessableEntity, nil},
+		{"remove missing field", mediaTypeJSONPatch, `[{"op": "remove", "path": "/priority"}]`, http.StatusBadRequest, nil},
+		{"nested path", mediaTypeJSONPatch, `[{"op": "replace", "path": "/title/0", "value": "x"}]`, http.StatusBadRequest, nil},
+		{"unknown op", mediaTypeJSONPatch, `[{"op": "increment", "path": "/title"}]`, http.StatusBadRequest, nil},
+		{"JSON patch that isn't an array", mediaTypeJSONPatch, `{"op": "remove", "path": "/title"}`, http.StatusBadRequest, nil},
+
+		{"unsupported media type", "text/plain", `title=x`, http.StatusUnsupportedMediaType, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			user := createTestUser(t, "patcher")
+			task := createTestTask(t, user, "Write report")
+			database.DB.Model(&task).Updates(map[string]any{"description": "First draft", "due_date": due})
+
+			r := routerAs(user)
+			r.PATCH("/tasks/:id", PatchTask)
+			w := doJSON(r, http.MethodPatch, "/tasks/1", tt.body, "Content-Type", tt.contentType)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", w.Code, w.Body, tt.wantStatus)
+			}
+
+			var after model.Task
+			database.DB.Where("user_id = ? AND task_id = ?", user.ID, task.TaskID).First(&after)
+			if tt.wantStatus != http.StatusOK {
+				if after.Version != 1 || after.Title != "Write report" || after.Description != "First draft" {
+					t.Errorf("rejected patch changed the task: %+v", after)
+				}
+				if tt.wantStatus == http.StatusUnsupportedMediaType && w.Header().Get("Accept-Patch") == "" {
+					t.Error("415 without an Accept-Patch header")
+				}
+				return
+			}
+			if after.Version != 2 {
+				t.Errorf("version = %d, want 2", after.Version)
+			}
+			tt.check(t, after)
+		})
+	}
+}
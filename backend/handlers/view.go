@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+// ✅ List Saved Views of the Logged-In User
+func GetViews(c *gin.Context) {
+	var views []model.SavedView
+	database.DB.Where("user_id = ?", c.GetUint("user_id")).Order("name").Find(&views)
+
+	c.JSON(http.StatusOK, views)
+}
+
+// ✅ Save a Named Filter
+func CreateView(c *gin.Context) {
+	var view model.SavedView
+	if err := c.ShouldBindJSON(&view); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view.ID = 0
+	view.UserID = c.GetUint("user_id")
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	if err := validateTaskFilter(view.Filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&view).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A view with this name already exists"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, view)
+}
+
+// ✅ Get a Saved View
+func GetView(c *gin.Context) {
+	view, ok := findView(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// ✅ Rename a View or Replace Its Filter
+func UpdateView(c *gin.Context) {
+	view, ok := findView(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name   *string           `json:"name"`
+		Filter *model.TaskFilter `json:"filter"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if body.Name != nil {
+		view.Name = strings.TrimSpace(*body.Name)
+		if view.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+			return
+		}
+	}
+	if body.Filter != nil {
+		if err := validateTaskFilter(*body.Filter); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		view.Filter = *body.Filter
+	}
+
+	if err := database.DB.Save(&view).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A view with this name already exists"})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// ✅ Delete a Saved View
+func DeleteView(c *gin.Context) {
+	view, ok := findView(c)
+	if !ok {
+		return
+	}
+
+	database.DB.Delete(&view)
+
+	c.JSON(http.StatusOK, gin.H{"message": "View deleted successfully"})
+}
+
+// ✅ Evaluate a Saved View Against the User's Tasks
+func GetViewTasks(c *gin.Context) {
+	view, ok := findView(c)
+	if !ok {
+		return
+	}
+
+	var tasks []model.Task
+	applyTaskFilter(database.DB.Where("user_id = ?", view.UserID), view.Filter).Find(&tasks)
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// findView loads the view in the :id param, writing the error response if it isn't the caller's
+func findView(c *gin.Context) (model.SavedView, bool) {
+	var view model.SavedView
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view ID"})
+		return view, false
+	}
+
+	if err := database.DB.Where("id = ? AND user_id = ?", id, c.GetUint("user_id")).First(&view).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return view, false
+	}
+	return view, true
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// ✅ List Watchers of a Task (Owner Only)
+func GetWatchers(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var task model.Task
+	if err := database.DB.Where("user_id = ? AND task_id = ?", userID, taskID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or does not belong to you"})
+		return
+	}
+
+	var watchers []model.TaskWatcher
+	database.DB.Where("owner_id = ? AND task_id = ?", task.UserID, task.TaskID).Find(&watchers)
+
+	c.JSON(http.StatusOK, watchers)
+}
+
+// ✅ Watch Your Own Task, or Invite Someone Else to Watch It
+func AddWatcher(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var body struct {
+		UserID uint `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if body.UserID == 0 {
+		body.UserID = userID
+	}
+
+	var task model.Task
+	if err := database.DB.Where("user_id = ? AND task_id = ?", userID, taskID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or does not belong to you"})
+		return
+	}
+
+	if body.UserID == userID {
+		if err := services.WatchTask(task.UserID, task.TaskID, userID, services.WatchReasonManual); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add watcher"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"message": "Watcher added"})
+		return
+	}
+
+	// ✅ Other users only watch once they accept, and get the same answer whether they exist or not
+	if err := services.InviteWatcher(task, c.MustGet("user").(model.User), body.UserID); err != nil {
+		log.Println("❌ Failed to invite watcher:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite watcher"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Invitation sent"})
+}
+
+// ✅ List Invitations to Watch Other Users' Tasks
+func GetWatchInvites(c *gin.Context) {
+	invites, err := services.PendingWatchInvites(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invites)
+}
+
+// ✅ Accept an Invitation (Starts Watching the Task)
+func AcceptWatchInvite(c *gin.Context) {
+	inviteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	err = services.AcceptWatchInvite(uint(inviteID), c.GetUint("user_id"))
+	if errors.Is(err, services.ErrWatchInviteNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Now watching task"})
+}
+
+// ✅ Decline an Invitation
+func DeclineWatchInvite(c *gin.Context) {
+	inviteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	err = services.DeclineWatchInvite(uint(inviteID), c.GetUint("user_id"))
+	if errors.Is(err, services.ErrWatchInviteNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// ✅ Remove a Watcher from a Task (Owner Only)
+func RemoveWatcher(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	watcherID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var task model.Task
+	if err := database.DB.Where("user_id = ? AND task_id = ?", userID, taskID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or does not belong to you"})
+		return
+	}
+
+	if err := services.UnwatchTask(task.UserID, task.TaskID, uint(watcherID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove watcher"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Watcher removed"})
+}
+
+// ✅ List Tasks the Logged-In User Is Watching
+func GetWatching(c *gin.Context) {
+	var watching []model.TaskWatcher
+	database.DB.Where("user_id = ?", c.GetUint("user_id")).Find(&watching)
+
+	c.JSON(http.StatusOK, watching)
+}
+
+// ✅ Stop Watching a Task (Works for Tasks Owned by Someone Else)
+func Unwatch(c *gin.Context) {
+	ownerID, err := strconv.Atoi(c.Param("owner_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid owner ID"})
+		return
+	}
+
+	taskID, err := strconv.Atoi(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	if err := services.UnwatchTask(uint(ownerID), uint(taskID), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unwatch task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "No longer watching task"})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+func TestAddWatcherInvitesOtherUsers(t *testing.T) {
+	setupTestDB(t)
+	owner := createTestUser(t, "owner")
+	invitee := createTestUser(t, "invitee")
+	task := createTestTask(t, owner, "Quarterly report")
+
+	r := routerAs(owner)
+	r.POST("/tasks/:id/watchers", AddWatcher)
+	path := fmt.Sprintf("/tasks/%d/watchers", task.TaskID)
+
+	existing := doJSON(r, http.MethodPost, path, map[string]uint{"user_id": invitee.ID})
+	unknown := doJSON(r, http.MethodPost, path, map[string]uint{"user_id": 9999})
+	if existing.Code != http.StatusAccepted || unknown.Code != existing.Code || unknown.Body.String() != existing.Body.String() {
+		t.Fatalf("existing = %d %s, unknown = %d %s; want the same 202", existing.Code, existing.Body, unknown.Code, unknown.Body)
+	}
+
+	var watchers, emails int64
+	database.DB.Model(&model.TaskWatcher{}).Where("user_id = ?", invitee.ID).Count(&watchers)
+	database.DB.Model(&model.OutboxEmail{}).Count(&emails)
+	if watchers != 0 || emails != 0 {
+		t.Fatalf("invitee watchers = %d, emails = %d before accepting; want 0 and 0", watchers, emails)
+	}
+
+	// ✅ Inviting again doesn't pile up notifications
+	doJSON(r, http.MethodPost, path, map[string]uint{"user_id": invitee.ID})
+	var notifications int64
+	database.DB.Model(&model.Notification{}).Where("user_id = ? AND event = ?", invitee.ID, "watch_invite").Count(&notifications)
+	if notifications != 1 {
+		t.Fatalf("invite notifications = %d, want 1", notifications)
+	}
+
+	ri := routerAs(invitee)
+	ri.GET("/watch-invites", GetWatchInvites)
+	ri.POST("/watch-invites/:id/accept", AcceptWatchInvite)
+	w := doJSON(ri, http.MethodGet, "/watch-invites", nil)
+	var invites []model.WatchInvite
+	if err := json.Unmarshal(w.Body.Bytes(), &invites); err != nil || len(invites) != 1 || invites[0].TaskTitle != task.Title {
+		t.Fatalf("GET /watch-invites = %s, want one invite for %q", w.Body, task.Title)
+	}
+
+	// ✅ Only the invitee can accept
+	if w := doJSON(r, http.MethodPost, "/tasks/1/watchers", map[string]uint{"user_id": owner.ID}); w.Code != http.StatusCreated {
+		t.Fatalf("watching your own task = %d, want 201", w.Code)
+	}
+	ro := routerAs(owner)
+	ro.POST("/watch-invites/:id/accept", AcceptWatchInvite)
+	if w := doJSON(ro, http.MethodPost, fmt.Sprintf("/watch-invites/%d/accept", invites[0].ID), nil); w.Code != http.StatusNotFound {
+		t.Fatalf("owner accepting the invite = %d, want 404", w.Code)
+	}
+
+	if w := doJSON(ri, http.MethodPost, fmt.Sprintf("/watch-invites/%d/accept", invites[0].ID), nil); w.Code != http.StatusOK {
+		t.Fatalf("accept = %d %s, want 200", w.Code, w.Body)
+	}
+	database.DB.Model(&model.TaskWatcher{}).Where("user_id = ?", invitee.ID).Count(&watchers)
+	if watchers != 1 {
+		t.Fatalf("invitee watchers after accepting = %d, want 1", watchers)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// ✅ List Webhooks of the Logged-In User
+func GetWebhooks(c *gin.Context) {
+	var hooks []model.Webhook
+	database.DB.Where("user_id = ?", c.GetUint("user_id")).Order("id").Find(&hooks)
+
+	c.JSON(http.StatusOK, hooks)
+}
+
+// ✅ Register a Webhook (The Signing Secret Is Only Returned Here)
+func CreateWebhook(c *gin.Context) {
+	var body struct {
+		URL    string   `json:"url" binding:"required"`
+		Events []string `json:"events"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.ValidateWebhookURL(body.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, event := range body.Events {
+		if !slices.Contains(services.WebhookEvents, event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event: " + event, "events": services.WebhookEvents})
+			return
+		}
+	}
+
+	secret, err := services.NewWebhookSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	hook := model.Webhook{
+		UserID: c.GetUint("user_id"),
+		URL:    body.URL,
+		Secret: secret,
+		Events: strings.Join(body.Events, ","),
+		Active: true,
+	}
+	if err := database.DB.Create(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"webhook": hook, "secret": secret})
+}
+
+// ✅ Delete a Webhook (Its Delivery Log Goes With It)
+func DeleteWebhook(c *gin.Context) {
+	hook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	database.DB.Where("webhook_id = ?", hook.ID).Delete(&model.WebhookDelivery{})
+	database.DB.Delete(&hook)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// ✅ Delivery Log of a Webhook (Newest First)
+func GetWebhookDeliveries(c *gin.Context) {
+	hook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	var deliveries []model.WebhookDelivery
+	database.DB.Where("webhook_id = ?", hook.ID).Order("id DESC").Limit(100).Find(&deliveries)
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ✅ Manually Redeliver a Past Delivery
+func RedeliverWebhook(c *gin.Context) {
+	hook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	var original model.WebhookDelivery
+	if err := database.DB.Where("id = ? AND webhook_id = ?", deliveryID, hook.ID).First(&original).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	delivery, err := services.RedeliverWebhook(original)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue redelivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// findWebhook loads the webhook in the :id param, writing the error response if it isn't the caller's
+func findWebhook(c *gin.Context) (model.Webhook, bool) {
+	var hook model.Webhook
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return hook, false
+	}
+
+	if err := database.DB.Where("id = ? AND user_id = ?", id, c.GetUint("user_id")).First(&hook).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return hook, false
+	}
+	return hook, true
+}
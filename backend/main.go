@@ -11,32 +11,66 @@ import (
 	"github.com/tarun05rawat/go-task-management/database"
 	"github.com/tarun05rawat/go-task-management/handlers"
 	"github.com/tarun05rawat/go-task-management/middleware"
+	"github.com/tarun05rawat/go-task-management/model"
 	"github.com/tarun05rawat/go-task-management/services"
 )
 
 func main() {
+	// ✅ Load Token Signing Keys (Fails Fast if None Are Configured)
+	services.InitSigningKeys()
+
 	// ✅ Connect to Database
 	database.ConnectToDb()
 
-	// ✅ Initialize S3 Client
+	// ✅ Initialize S3 Client & Move Attachments Out of the Old Layout
 	services.InitS3()
+	go services.MigrateLegacyAttachments()
+
+	// ✅ Start Background Mail Delivery & Due-Soon Reminders
+	services.InitMailer()
+	services.StartMailWorker()
+	services.StartDueSoonReminders()
+
+	// ✅ Start Background Webhook Delivery
+	services.StartWebhookWorker()
+
+	// ✅ Build Requested Data Exports in the Background
+	services.StartExportWorker()
+
+	// ✅ Listen for Task Events From Every Backend Instance
+	services.StartEventListener()
+
+	// ✅ Track Failed Logins for Lockout
+	services.InitLoginGuard()
 
-	// ✅ Initialize Gin Router
-	r := gin.Default()
+	// ✅ Initialize Gin Router (Access Log Keeps Secret URLs Out)
+	r := gin.New()
+	r.Use(middleware.RequestLogger(), gin.Recovery())
 
 	// ✅ Configure CORS Middleware
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"}, // ✅ Allow only frontend origin
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Authorization"},
-		AllowCredentials: true,           // ✅ Allow credentials (tokens/cookies)
-		MaxAge:           12 * time.Hour, // Cache preflight requests
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", "If-Match", "If-None-Match"},
+		ExposeHeaders:    []string{"ETag"}, // ✅ Let the frontend read task versions
+		AllowCredentials: true,             // ✅ Allow credentials (tokens/cookies)
+		MaxAge:           12 * time.Hour,   // Cache preflight requests
 	}))
 
 	// ✅ Public Routes (No Authentication Required)
 	r.POST("/signup", controllers.Signup)
 	r.POST("/login", controllers.Login)
+	r.POST("/login/mfa", controllers.LoginMFA)
+	r.GET("/auth/oidc/login", controllers.OIDCLogin)
+	r.GET("/auth/oidc/callback", controllers.OIDCCallback)
 	r.POST("/logout", controllers.Logout)
+	r.POST("/token/refresh", controllers.RefreshToken)
+	r.POST("/verify-email", controllers.VerifyEmail)
+	r.POST("/verify-email/resend", controllers.ResendVerification)
+	r.POST("/password/forgot", controllers.ForgotPassword)
+	r.POST("/password/reset", controllers.ResetPassword)
+	r.GET("/.well-known/jwks.json", controllers.JWKS)
+	r.GET("/calendar/:file", handlers.ServeCalendarFeed) // ✅ Subscribed .ics feed, the token in the URL authenticates
 
 	// ✅ Protected Routes (Require Authentication)
 	protected := r.Group("/")
@@ -44,24 +78,124 @@ func main() {
 
 	// ✅ Authentication validation
 	protected.GET("/validate", controllers.Validate)
+	protected.GET("/me", controllers.GetMe)
+
+	// ✅ Account Routes (Logged-In Sessions Only, Not API Tokens)
+	account := protected.Group("/")
+	account.Use(middleware.RequireSession)
+
+	// ✅ Profile & Account Self-Service
+	account.PATCH("/me", controllers.UpdateMe)
+	account.POST("/me/avatar", controllers.UploadAvatar)
+	account.DELETE("/me", controllers.DeleteMe)
+	account.POST("/me/export", controllers.RequestExport)
+	account.GET("/me/exports", controllers.GetExports)
+	account.GET("/me/exports/:id/download", controllers.DownloadExport)
+
+	// ✅ Change Password (Requires the Current One)
+	account.POST("/me/password", controllers.ChangePassword)
+
+	// ✅ Active Sessions (List, Revoke One, Sign Out Everywhere)
+	account.GET("/sessions", controllers.GetSessions)
+	account.DELETE("/sessions/:id", controllers.RevokeSession)
+	account.DELETE("/sessions", controllers.RevokeAllSessions)
+
+	// ✅ Personal API Tokens
+	account.GET("/api-tokens", controllers.GetAPITokens)
+	account.POST("/api-tokens", controllers.CreateAPIToken)
+	account.DELETE("/api-tokens/:id", controllers.RevokeAPIToken)
+
+	// ✅ Calendar Feed Subscriptions (Secret .ics URLs)
+	account.GET("/calendar-feeds", handlers.GetCalendarFeeds)
+	account.POST("/calendar-feeds", handlers.CreateCalendarFeed)
+	account.DELETE("/calendar-feeds/:id", handlers.RevokeCalendarFeed)
+
+	// ✅ Two-Factor Authentication (TOTP)
+	account.GET("/mfa", controllers.GetMFAStatus)
+	account.POST("/mfa/totp/enroll", controllers.EnrollTOTP)
+	account.POST("/mfa/totp/confirm", controllers.ConfirmTOTP)
+	account.POST("/mfa/totp/disable", controllers.DisableTOTP)
+	account.POST("/mfa/recovery-codes", controllers.RegenerateRecoveryCodes)
+
+	// ✅ Notification Preferences
+	account.GET("/notifications/preferences", handlers.GetNotificationPreferences)
+	account.PUT("/notifications/preferences", handlers.UpdateNotificationPreferences)
+
+	// ✅ Outgoing Webhooks
+	account.GET("/webhooks", handlers.GetWebhooks)
+	account.POST("/webhooks", handlers.CreateWebhook)
+	account.DELETE("/webhooks/:id", handlers.DeleteWebhook)
+	account.GET("/webhooks/:id/deliveries", handlers.GetWebhookDeliveries)
+	account.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", handlers.RedeliverWebhook)
+
+	// ✅ Admin Routes (API Tokens Need the `admin` Scope)
+	admin := protected.Group("/")
+	admin.Use(middleware.RequireScope(model.ScopeAdmin))
+
+	// ✅ Admin-only User Management (Roles, Disable, Force Logout, Impersonation)
+	admin.GET("/users", middleware.RequirePermission(model.PermUsersRead), controllers.GetAllUsers)
+	admin.GET("/users/:id", middleware.RequirePermission(model.PermUsersRead), controllers.GetUser)
+	admin.PUT("/users/:id/role", middleware.RequirePermission(model.PermUsersManageRoles), controllers.SetUserRole)
+	admin.POST("/users/:id/unlock", middleware.RequirePermission(model.PermUsersManage), controllers.UnlockUser)
+	admin.POST("/users/:id/disable", middleware.RequirePermission(model.PermUsersManage), controllers.DisableUser)
+	admin.POST("/users/:id/enable", middleware.RequirePermission(model.PermUsersManage), controllers.EnableUser)
+	admin.POST("/users/:id/logout", middleware.RequirePermission(model.PermUsersManage), controllers.ForceLogoutUser)
+	admin.POST("/users/:id/impersonate", middleware.RequirePermission(model.PermUsersImpersonate), controllers.ImpersonateUser)
+
+	// ✅ Admin-only Audit Trail
+	admin.GET("/audit-logs", middleware.RequirePermission(model.PermAuditRead), controllers.GetAuditLogs)
+
+	// ✅ Attachment Uploads (API Tokens Need `attachments:write`)
+	attachments := protected.Group("/")
+	attachments.Use(middleware.RequireScope(model.ScopeAttachmentsWrite))
 
 	// ✅ Upload Task Attachments
-	protected.POST("/tasks/:id/upload", controllers.UploadFiles)
+	attachments.POST("/tasks/:id/upload", controllers.UploadFiles)
 
-	// ✅ Admin-only Route to View All Users
-	protected.GET("/users", controllers.GetAllUsers)
+	// ✅ Task Routes (API Tokens Need `tasks:read` to Read, `tasks:write` to Change)
+	tasks := protected.Group("/")
+	tasks.Use(middleware.RequireScopeByMethod(model.ScopeTasksRead, model.ScopeTasksWrite))
 
 	// ✅ List Task Attachments
-	protected.GET("/tasks/:id/attachments", controllers.ListAttachments)
+	tasks.GET("/tasks/:id/attachments", controllers.ListAttachments)
+
+	// ✅ Task Watchers (Other Users Must Accept an Invitation)
+	tasks.GET("/tasks/:id/watchers", handlers.GetWatchers)
+	tasks.POST("/tasks/:id/watchers", handlers.AddWatcher)
+	tasks.DELETE("/tasks/:id/watchers/:user_id", handlers.RemoveWatcher)
+	tasks.GET("/watching", handlers.GetWatching)
+	tasks.DELETE("/watching/:owner_id/:task_id", handlers.Unwatch)
+	tasks.GET("/watch-invites", handlers.GetWatchInvites)
+	tasks.POST("/watch-invites/:id/accept", handlers.AcceptWatchInvite)
+	tasks.DELETE("/watch-invites/:id", handlers.DeclineWatchInvite)
+
+	// ✅ Notifications
+	tasks.GET("/notifications", handlers.GetNotifications)
+	tasks.POST("/notifications/:id/read", handlers.MarkNotificationRead)
+
+	// ✅ Saved Views (Named Task Filters)
+	tasks.GET("/views", handlers.GetViews)
+	tasks.POST("/views", handlers.CreateView)
+	tasks.GET("/views/:id", handlers.GetView)
+	tasks.PUT("/views/:id", handlers.UpdateView)
+	tasks.DELETE("/views/:id", handlers.DeleteView)
+	tasks.GET("/views/:id/tasks", handlers.GetViewTasks)
+
+	// ✅ Real-Time Task Events (Server-Sent Events)
+	tasks.GET("/events", handlers.StreamEvents)
 
 	// ✅ Task Management Routes (For Authenticated Users)
-	taskRoutes := protected.Group("/tasks") // ✅ This groups all task routes under `/tasks`
+	taskRoutes := tasks.Group("/tasks") // ✅ This groups all task routes under `/tasks`
 	{
-		taskRoutes.POST("/", handlers.CreateTask)      // ✅ Create Task
-		taskRoutes.GET("/", handlers.GetTasks)         // ✅ Get All Tasks (for logged-in user)
-		taskRoutes.GET("/:id", handlers.GetTaskByID)   // ✅ Get Specific Task
-		taskRoutes.PUT("/:id", handlers.UpdateTask)    // ✅ Update Task
-		taskRoutes.DELETE("/:id", handlers.DeleteTask) // ✅ Delete Task
+		taskRoutes.POST("/", handlers.CreateTask)          // ✅ Create Task
+		taskRoutes.POST("/import", handlers.ImportTasks)   // ✅ Import Tasks (CSV, JSON, Trello, Todoist)
+		taskRoutes.POST("/bulk", handlers.BulkUpdateTasks) // ✅ Apply One Operation to Many Tasks
+		taskRoutes.GET("/export", handlers.ExportTasks)    // ✅ Export Tasks (CSV, JSON, Markdown, iCalendar)
+		taskRoutes.GET("/", handlers.GetTasks)             // ✅ Get All Tasks (for logged-in user)
+		taskRoutes.GET("/:id", handlers.GetTaskByID)       // ✅ Get Specific Task
+		taskRoutes.PUT("/:id", handlers.UpdateTask)        // ✅ Update Task
+		taskRoutes.PATCH("/:id", handlers.PatchTask)       // ✅ Partially Update Task (Merge Patch or JSON Patch)
+		taskRoutes.DELETE("/:id", handlers.DeleteTask)     // ✅ Delete Task
 	}
 
 	// ✅ Start Server
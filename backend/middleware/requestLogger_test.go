@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLoggerRedactsFeedTokens(t *testing.T) {
+	var out bytes.Buffer
+	r := gin.New()
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{Formatter: redactedLogFormat, Output: &out}))
+	r.GET("/calendar/:file", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/calendar/tm_cal_s3cret.ics?x=1", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tasks?status=done", nil))
+
+	log := out.String()
+	if strings.Contains(log, "s3cret") {
+		t.Fatalf("log contains the feed token:\n%s", log)
+	}
+	if !strings.Contains(log, `"/calendar/[redacted]"`) || !strings.Contains(log, `"/tasks?status=done"`) {
+		t.Fatalf("unexpected log:\n%s", log)
+	}
+}
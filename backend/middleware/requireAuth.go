@@ -1,45 +1,59 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/tarun05rawat/go-task-management/database"
 	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
 )
 
-// RequireAuth Middleware (Extracts JWT from cookies)
+// tokenFromRequest returns the JWT sent by the client. An `Authorization:
+// Bearer <jwt>` header wins over the cookie; a header that is present but not
+// a Bearer token is rejected instead of silently falling back to the cookie.
+func tokenFromRequest(c *gin.Context) (string, error) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		token = strings.TrimSpace(token)
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return "", errors.New("Authorization header must be 'Bearer <token>'")
+		}
+		return token, nil
+	}
+
+	token, err := c.Cookie("Authorization")
+	if err != nil || token == "" {
+		return "", errors.New("Missing authentication token")
+	}
+	return token, nil
+}
+
+// RequireAuth Middleware (Extracts JWT from the Authorization header or cookie)
 func RequireAuth(c *gin.Context) {
-	tokenString, err := c.Cookie("Authorization")
+	tokenString, err := tokenFromRequest(c)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing authentication token"})
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	secret := os.Getenv("SECRET")
-	if secret == "" {
-		secret = "your-fallback-secret-key"
+	// ✅ Personal API tokens are opaque, everything else must be a JWT
+	if strings.HasPrefix(tokenString, model.APITokenPrefix) {
+		authenticateAPIToken(c, tokenString)
+		return
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
-
-	if err != nil || !token.Valid {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+	claims, err := services.ParseAccessToken(tokenString)
+	if errors.Is(err, services.ErrAccessTokenExpired) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
 		return
 	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
 	}
 
@@ -55,18 +69,63 @@ func RequireAuth(c *gin.Context) {
 		return
 	}
 
-	// ✅ Debug Log to Check User ID
-	fmt.Println("DEBUG: Authenticated user ID from token:", uint(userID))
+	// ✅ Reject tokens whose session was logged out or revoked
+	sid, ok := claims["sid"].(string)
+	if !ok || !services.CheckSession(sid) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return
+	}
 
 	var user model.User
 	if err := database.DB.First(&user, uint(userID)).Error; err != nil || user.ID == 0 {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 		return
 	}
+	if user.DisabledAt != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+		return
+	}
 
 	// ✅ Attach Correct User ID to Context
 	c.Set("user", user)
 	c.Set("user_id", uint(userID))
+	c.Set("session_id", sid)
+	c.Set("auth_type", AuthTypeSession)
+
+	// ✅ Support sessions: record everything the admin changes as this user
+	if impersonator, ok := claims["imp"].(float64); ok {
+		adminID := uint(impersonator)
+		c.Set("impersonator_id", adminID)
+		c.Next()
+
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			services.Audit(services.AuditImpersonatedRequest, &adminID, &user.ID, c.ClientIP(),
+				fmt.Sprintf("%s %s -> %d", c.Request.Method, c.Request.URL.Path, c.Writer.Status()))
+		}
+		return
+	}
+
+	c.Next()
+}
+
+// authenticateAPIToken is RequireAuth for personal API tokens
+func authenticateAPIToken(c *gin.Context, tokenString string) {
+	token, user, err := services.AuthenticateAPIToken(tokenString)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API token"})
+		return
+	}
+
+	if user.DisabledAt != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+		return
+	}
+
+	c.Set("user", user)
+	c.Set("user_id", user.ID)
+	c.Set("api_token_id", token.ID)
+	c.Set("scopes", services.TokenScopes(token))
+	c.Set("auth_type", AuthTypeAPIToken)
 
 	c.Next()
 }
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/model"
+	"github.com/tarun05rawat/go-task-management/services"
+)
+
+// RequirePermission allows the request only if the user's role grants every
+// listed permission. It must run after RequireAuth.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := c.MustGet("user").(model.User)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		// ✅ Admin powers can be made to depend on two-factor authentication
+		if user.Role == model.RoleAdmin && services.RequireAdminMFA() && !services.HasTOTP(user.ID) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Enable two-factor authentication to use admin features"})
+			return
+		}
+
+		for _, permission := range permissions {
+			if !model.HasPermission(user.Role, permission) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// permissionRouter serves GET /admin behind RequirePermission, as the given user
+func permissionRouter(user model.User, permissions ...string) *gin.Engine {
+	r := gin.New()
+	r.GET("/admin", func(c *gin.Context) {
+		c.Set("user", user)
+		c.Set("user_id", user.ID)
+		c.Next()
+	}, RequirePermission(permissions...), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	return r
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		permissions []string
+		want        int
+	}{
+		{"user is denied admin permissions", model.RoleUser, []string{model.PermUsersManageRoles}, http.StatusForbidden},
+		{"user is denied user listing", model.RoleUser, []string{model.PermUsersRead}, http.StatusForbidden},
+		{"unknown role is denied", "superuser", []string{model.PermUsersRead}, http.StatusForbidden},
+		{"admin is allowed", model.RoleAdmin, []string{model.PermUsersManageRoles}, http.StatusOK},
+		{"admin needs every permission listed", model.RoleAdmin, []string{model.PermUsersRead, "tasks:nuke"}, http.StatusForbidden},
+		{"no permissions listed", model.RoleUser, nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := model.User{Username: "alice", Role: tt.role}
+			user.ID = 1
+
+			w := httptest.NewRecorder()
+			permissionRouter(user, tt.permissions...).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
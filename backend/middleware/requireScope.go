@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// How a request was authenticated (set by RequireAuth as "auth_type")
+const (
+	AuthTypeSession  = "session"
+	AuthTypeAPIToken = "api_token"
+)
+
+// RequireScope rejects API tokens that weren't granted the scope. Browser
+// sessions act with the user's full rights and always pass.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasScope(c, scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API token lacks the " + scope + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScopeByMethod requires the read scope for GET/HEAD and the write scope for everything else
+func RequireScopeByMethod(read, write string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := write
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = read
+		}
+		if !hasScope(c, scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API token lacks the " + scope + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession keeps API tokens (tokens can't mint tokens) and impersonated
+// sessions away from account management
+func RequireSession(c *gin.Context) {
+	if c.GetString("auth_type") != AuthTypeSession {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This endpoint requires a logged-in session"})
+		return
+	}
+
+	// ✅ Support staff can see what the user sees, but not change their credentials
+	if _, impersonating := c.Get("impersonator_id"); impersonating {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not available while impersonating"})
+		return
+	}
+	c.Next()
+}
+
+func hasScope(c *gin.Context, scope string) bool {
+	if c.GetString("auth_type") != AuthTypeAPIToken {
+		return true
+	}
+	return slices.Contains(c.GetStringSlice("scopes"), scope)
+}
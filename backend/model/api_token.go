@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Scopes an API token can be granted
+const (
+	ScopeTasksRead        = "tasks:read"
+	ScopeTasksWrite       = "tasks:write"
+	ScopeAttachmentsWrite = "attachments:write"
+	ScopeAdmin            = "admin"
+)
+
+// AllScopes lists every valid API token scope
+var AllScopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeAttachmentsWrite, ScopeAdmin}
+
+// APITokenPrefix marks personal API tokens so RequireAuth can tell them from JWTs
+const APITokenPrefix = "tm_pat_"
+
+// APIToken is a named, long-lived personal token for scripts and CI jobs
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	Name       string     `gorm:"not null" json:"name"`
+	Hint       string     `json:"hint"`                          // ✅ Last characters of the token, to tell tokens apart
+	TokenHash  string     `gorm:"uniqueIndex;not null" json:"-"` // ✅ SHA-256 of the token, the token itself is never stored
+	Scopes     string     `gorm:"not null" json:"scopes"`        // Comma-separated
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// AuditLog records a security-relevant event
+type AuditLog struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Action       string    `gorm:"index;not null" json:"action"`
+	ActorID      *uint     `gorm:"index" json:"actor_id"`       // Who did it (nil for the system)
+	TargetUserID *uint     `gorm:"index" json:"target_user_id"` // Who it was done to
+	IP           string    `json:"ip"`
+	Details      string    `json:"details"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+}
+
+// LoginAttempt tracks failed logins for one account or IP (the Postgres login attempt store)
+type LoginAttempt struct {
+	Key           string `gorm:"primaryKey"` // "account:<email>" or "ip:<address>"
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
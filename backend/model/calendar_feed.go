@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// CalendarFeedPrefix marks calendar feed tokens, which only appear in feed URLs
+const CalendarFeedPrefix = "tm_cal_"
+
+// CalendarFeed is a secret .ics URL calendar apps subscribe to. The token in
+// the URL is the only credential, so it only grants reading the user's tasks
+// (titles, descriptions, statuses and due dates) and can be revoked on its own.
+type CalendarFeed struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	UserID           uint       `gorm:"index;not null" json:"user_id"`
+	Name             string     `gorm:"not null" json:"name"`
+	Hint             string     `json:"hint"`                          // ✅ Last characters of the token, to tell feeds apart
+	TokenHash        string     `gorm:"uniqueIndex;not null" json:"-"` // ✅ SHA-256 of the token, the token itself is never stored
+	IncludeCompleted bool       `gorm:"not null;default:false" json:"include_completed"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// DataExport is a ZIP of everything stored about a user, built in the
+// background after they ask for it
+type DataExport struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"index;not null" json:"user_id"`
+	Status        string     `gorm:"index;not null;default:pending" json:"status"` // pending, ready, failed, expired
+	Attempts      int        `gorm:"not null;default:0" json:"-"`
+	NextAttemptAt time.Time  `gorm:"index" json:"-"`
+	FileKey       string     `json:"-"` // S3 key of the ZIP
+	SizeBytes     int64      `json:"size_bytes"`
+	LastError     string     `json:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	ExpiresAt     *time.Time `json:"expires_at"` // The ZIP is deleted after this
+}
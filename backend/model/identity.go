@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// UserIdentity links a user to an account at an external OpenID Connect provider
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	Issuer    string    `gorm:"uniqueIndex:idx_identity_issuer_subject;not null" json:"issuer"`
+	Subject   string    `gorm:"uniqueIndex:idx_identity_issuer_subject;not null" json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
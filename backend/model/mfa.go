@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// TOTPCredential is a user's authenticator app secret (RFC 6238). It only
+// protects logins once ConfirmedAt is set.
+type TOTPCredential struct {
+	UserID       uint   `gorm:"primaryKey"`
+	Secret       string `gorm:"not null"` // Base32, as shown to the authenticator app
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 // ✅ Time step of the last accepted code, so a code can't be replayed
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RecoveryCode is a one-time code that stands in for a TOTP code
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"not null"` // ✅ SHA-256 of the code, the code itself is never stored
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// MFAChallenge is a pending second login step, started once the password
+// checked out. Its ID travels in the signed challenge token; the row limits
+// how many codes may be tried and makes the token single-use.
+type MFAChallenge struct {
+	ID        string    `gorm:"primaryKey"` // Random hex, the token's jti
+	UserID    uint      `gorm:"index;not null"`
+	Attempts  int       `gorm:"not null;default:0"` // Codes tried so far
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
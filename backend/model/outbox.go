@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// OutboxEmail is an email waiting to be delivered by the mail worker
+type OutboxEmail struct {
+	ID            uint      `gorm:"primaryKey"`
+	To            string    `gorm:"not null"`
+	Subject       string    `gorm:"not null"`
+	Body          string    `gorm:"type:text;not null"`
+	Status        string    `gorm:"index;not null;default:pending"` // pending, sent, failed
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+}
@@ -0,0 +1,30 @@
+package model
+
+import "slices"
+
+// Roles a user can hold (assigned by the server, never chosen at signup)
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Permissions checked by middleware.RequirePermission
+const (
+	PermUsersRead        = "users:read"
+	PermUsersManageRoles = "users:manage_roles"
+	PermUsersManage      = "users:manage" // Account operations like unlocking, disabling, force logout
+	PermUsersImpersonate = "users:impersonate"
+	PermAuditRead        = "audit:read"
+)
+
+// RolePermissions maps each role to what it may do. Every user may manage
+// their own tasks, so task access isn't a permission.
+var RolePermissions = map[string][]string{
+	RoleUser:  {},
+	RoleAdmin: {PermUsersRead, PermUsersManageRoles, PermUsersManage, PermUsersImpersonate, PermAuditRead},
+}
+
+// HasPermission reports whether the role grants the permission
+func HasPermission(role, permission string) bool {
+	return slices.Contains(RolePermissions[role], permission)
+}
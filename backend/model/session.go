@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Session is one login on one device. Its ID is the refresh token family
+// and the `sid` claim of every access token issued for it.
+type Session struct {
+	ID             string     `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"index;not null" json:"user_id"`
+	UserAgent      string     `json:"user_agent"`
+	IP             string     `json:"ip"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastSeenAt     time.Time  `json:"last_seen_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	ImpersonatorID *uint      `json:"impersonator_id,omitempty"` // Admin acting as this user in a support session
+}
@@ -4,13 +4,19 @@ import (
 	"time"
 )
 
+// StatusCompleted is the status of a finished task
+const StatusCompleted = "completed"
+
 // Task struct
 type Task struct {
-	UserID      uint      `gorm:"primaryKey" json:"user_id"` // ✅ Composite Primary Key
-	TaskID      uint      `gorm:"primaryKey" json:"id"`      // ✅ Composite Primary Key
-	Title       string    `gorm:"not null" json:"title"`
-	Description string    `json:"description"`
-	Status      string    `gorm:"default:pending" json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	UserID         uint       `gorm:"primaryKey" json:"user_id"` // ✅ Composite Primary Key
+	TaskID         uint       `gorm:"primaryKey" json:"id"`      // ✅ Composite Primary Key
+	Title          string     `gorm:"not null" json:"title"`
+	Description    string     `json:"description"`
+	Status         string     `gorm:"default:pending" json:"status"`
+	DueDate        *time.Time `json:"due_date"`
+	ReminderSentAt *time.Time `json:"-"`                                 // ✅ Set once the due-soon email is queued
+	Version        uint       `gorm:"not null;default:1" json:"version"` // ✅ Bumped on every change, the task's ETag
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
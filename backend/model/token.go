@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// RefreshToken is an opaque, single-use token that can be exchanged for a
+// new access token. Tokens issued from the same login share a FamilyID.
+type RefreshToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	FamilyID  string `gorm:"index;not null"`
+	TokenHash string `gorm:"uniqueIndex;not null"` // ✅ SHA-256 of the token, the token itself is never stored
+	ExpiresAt time.Time
+	UsedAt    *time.Time // Set when rotated; presenting a used token again means it was stolen
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
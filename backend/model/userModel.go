@@ -1,14 +1,23 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
-	Username     string     `gorm:"unique;not null"`
-	Email        string     `gorm:"unique;not null"`
-	PasswordHash string     `gorm:"not null"`
-	Role         string     `gorm:"default:user" json:"role"`
-	UserData     []UserData `gorm:"foreignKey:UserID"`
+	Username        string     `gorm:"unique;not null"`
+	Email           string     `gorm:"unique;not null"`
+	PasswordHash    string     `gorm:"not null" json:"-"`
+	Role            string     `gorm:"default:user" json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	DisabledAt      *time.Time `json:"disabled_at"`                          // ✅ Set by an admin to block logins
+	AvatarKey       string     `json:"-"`                                    // S3 key of the profile picture
+	Timezone        string     `gorm:"not null;default:UTC" json:"timezone"` // IANA name, e.g. "Europe/Berlin"
+	Locale          string     `gorm:"not null;default:en" json:"locale"`    // BCP 47 tag, e.g. "en-GB"
+	UserData        []UserData `gorm:"foreignKey:UserID"`
 }
 
 type UserData struct {
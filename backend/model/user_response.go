@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// UserResponse is how a user is shown to admins. It's built field by field
+// so credentials (password hash, MFA secrets, tokens) can never leak into it.
+type UserResponse struct {
+	ID            uint       `json:"id"`
+	Username      string     `json:"username"`
+	Email         string     `json:"email"`
+	EmailVerified bool       `json:"email_verified"`
+	Role          string     `json:"role"`
+	Timezone      string     `json:"timezone"`
+	Locale        string     `json:"locale"`
+	DisabledAt    *time.Time `json:"disabled_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// NewUserResponse converts a User to its response DTO
+func NewUserResponse(user User) UserResponse {
+	return UserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          user.Role,
+		Timezone:      user.Timezone,
+		Locale:        user.Locale,
+		DisabledAt:    user.DisabledAt,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// What a UserToken may be used for
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposePasswordReset = "password_reset"
+)
+
+// UserToken is a single-use, expiring token emailed to a user
+type UserToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	Purpose   string `gorm:"not null"`
+	Email     string // Address the token was sent to (what gets verified)
+	TokenHash string `gorm:"uniqueIndex;not null"` // ✅ SHA-256 of the token, the token itself is never stored
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
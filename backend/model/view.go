@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// TaskFilter narrows and orders a user's task list
+type TaskFilter struct {
+	Status    []string   `json:"status,omitempty"`
+	DueAfter  *time.Time `json:"due_after,omitempty"`
+	DueBefore *time.Time `json:"due_before,omitempty"`
+	Query     string     `json:"query,omitempty"` // Matched against title and description
+	Sort      string     `json:"sort,omitempty"`  // Field name, prefix with "-" for descending
+}
+
+// SavedView is a named TaskFilter a user can re-run
+type SavedView struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"uniqueIndex:idx_saved_views_user_name;not null" json:"user_id"`
+	Name      string     `gorm:"uniqueIndex:idx_saved_views_user_name;not null" json:"name"`
+	Filter    TaskFilter `gorm:"type:jsonb;serializer:json" json:"filter"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
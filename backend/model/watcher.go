@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// TaskWatcher links a user to a task they want to hear about.
+// OwnerID + TaskID identify the task (same composite key as Task).
+type TaskWatcher struct {
+	OwnerID   uint      `gorm:"primaryKey" json:"owner_id"`
+	TaskID    uint      `gorm:"primaryKey" json:"task_id"`
+	UserID    uint      `gorm:"primaryKey;index" json:"user_id"`
+	Reason    string    `gorm:"not null;default:manual" json:"reason"` // creator, manual, assignee, commenter
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WatchInvite asks a user to watch someone else's task. Nobody watches a task
+// they don't own until they accept.
+type WatchInvite struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	OwnerID    uint       `gorm:"not null;uniqueIndex:idx_watch_invite" json:"owner_id"`
+	TaskID     uint       `gorm:"not null;uniqueIndex:idx_watch_invite" json:"task_id"`
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_watch_invite;index" json:"user_id"` // Invitee
+	TaskTitle  string     `gorm:"-" json:"task_title,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NotificationPreference controls which task events notify a user
+type NotificationPreference struct {
+	UserID           uint      `gorm:"primaryKey" json:"user_id"`
+	StatusChange     bool      `json:"status_change"`
+	NewComment       bool      `json:"new_comment"`
+	AttachmentUpload bool      `json:"attachment_upload"`
+	DueDate          bool      `json:"due_date"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// DefaultNotificationPreference returns the preferences used until a user saves their own
+func DefaultNotificationPreference(userID uint) NotificationPreference {
+	return NotificationPreference{
+		UserID:           userID,
+		StatusChange:     true,
+		NewComment:       true,
+		AttachmentUpload: true,
+		DueDate:          true,
+	}
+}
+
+// Notification is an in-app message produced by a watched task changing
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"` // Recipient
+	OwnerID   uint       `gorm:"not null" json:"owner_id"`
+	TaskID    uint       `gorm:"not null" json:"task_id"`
+	ActorID   uint       `json:"actor_id"`
+	Event     string     `gorm:"not null" json:"event"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
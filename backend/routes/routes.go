@@ -2,8 +2,9 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/tarun05rawat/go-task-management/handlers"
+	"github.com/tarun05rawat/go-task-management/controllers"
 	"github.com/tarun05rawat/go-task-management/middleware"
+	"github.com/tarun05rawat/go-task-management/model"
 )
 
 func SetupRoutes(router *gin.Engine) {
@@ -11,6 +12,6 @@ func SetupRoutes(router *gin.Engine) {
 
 	// ✅ Secure Route (Admin Only)
 	protected := api.Group("/")
-	protected.Use(middleware.RequireAuth)
-	protected.GET("/users", handlers.GetAllUsers) // ✅ Only Admins can view all users
+	protected.Use(middleware.RequireAuth, middleware.RequirePermission(model.PermUsersRead))
+	protected.GET("/users", controllers.GetAllUsers) // ✅ Only Admins can view all users
 }
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+	_ "time/tzdata" // ✅ Validate time zones even on images without a zoneinfo database
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTimezone = errors.New("unknown time zone, use an IANA name like \"Europe/Berlin\"")
+	ErrInvalidLocale   = errors.New("invalid locale, use a language tag like \"en\" or \"pt-BR\"")
+	ErrLastAdmin       = errors.New("cannot remove the last admin")
+)
+
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// ValidateTimezone checks an IANA time zone name
+func ValidateTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return ErrInvalidTimezone
+	}
+	return nil
+}
+
+// ValidateLocale checks the shape of a BCP 47 language tag
+func ValidateLocale(tag string) error {
+	if !localePattern.MatchString(tag) {
+		return ErrInvalidLocale
+	}
+	return nil
+}
+
+// DeleteAccount removes everything a user owns (tasks, watchers,
+// notifications, views, webhooks, calendar feeds, sessions, tokens, MFA, SSO
+// links) and anonymizes the user row, which is kept soft-deleted so audit
+// entries still point somewhere. Uploaded files are removed from S3 afterwards.
+func DeleteAccount(user model.User) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// ✅ Don't leave the system without an admin
+		if err := ensureAnotherAdmin(tx, user.ID); err != nil {
+			return err
+		}
+
+		webhooks := tx.Model(&model.Webhook{}).Select("id").Where("user_id = ?", user.ID)
+		deletes := []struct {
+			model any
+			query string
+			args  []any
+		}{
+			{&model.Task{}, "user_id = ?", []any{user.ID}},
+			{&model.TaskWatcher{}, "owner_id = ? OR user_id = ?", []any{user.ID, user.ID}},
+			{&model.WatchInvite{}, "owner_id = ? OR user_id = ?", []any{user.ID, user.ID}},
+			{&model.Notification{}, "owner_id = ? OR user_id = ?", []any{user.ID, user.ID}},
+			{&model.NotificationPreference{}, "user_id = ?", []any{user.ID}},
+			{&model.SavedView{}, "user_id = ?", []any{user.ID}},
+			{&model.WebhookDelivery{}, "webhook_id IN (?)", []any{webhooks}},
+			{&model.Webhook{}, "user_id = ?", []any{user.ID}},
+			{&model.RefreshToken{}, "user_id = ?", []any{user.ID}},
+			{&model.Session{}, "user_id = ?", []any{user.ID}},
+			{&model.APIToken{}, "user_id = ?", []any{user.ID}},
+			{&model.UserToken{}, "user_id = ?", []any{user.ID}},
+			{&model.TOTPCredential{}, "user_id = ?", []any{user.ID}},
+			{&model.RecoveryCode{}, "user_id = ?", []any{user.ID}},
+			{&model.MFAChallenge{}, "user_id = ?", []any{user.ID}},
+			{&model.UserIdentity{}, "user_id = ?", []any{user.ID}},
+			{&model.UserData{}, "user_id = ?", []any{user.ID}},
+			{&model.DataExport{}, "user_id = ?", []any{user.ID}},
+			{&model.CalendarFeed{}, "user_id = ?", []any{user.ID}},
+			{&model.OutboxEmail{}, `"to" = ? AND status = ?`, []any{user.Email, "pending"}},
+		}
+		for _, d := range deletes {
+			if err := tx.Unscoped().Where(d.query, d.args...).Delete(d.model).Error; err != nil {
+				return err
+			}
+		}
+
+		// ✅ Free the username and email for reuse and drop the personal data
+		placeholder := fmt.Sprintf("deleted-user-%d", user.ID)
+		if err := tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]any{
+			"username":          placeholder,
+			"email":             placeholder + "@deleted.invalid",
+			"password_hash":     "!",
+			"email_verified_at": nil,
+			"avatar_key":        "",
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.User{}, user.ID).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := LoginAttempts.Reset(accountAttemptKey(user.Email)); err != nil {
+		log.Println("❌ Failed to clear login attempts of deleted account:", err)
+	}
+	if err := DeleteS3Prefix(UserFilesPrefix(user.ID)); err != nil {
+		log.Println("❌ Failed to delete files of deleted account:", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+var ErrInvalidAPIToken = errors.New("invalid, expired or revoked API token")
+
+// CreateAPIToken stores a new token for the user and returns it with the
+// plaintext value, which is never retrievable again.
+func CreateAPIToken(userID uint, name string, scopes []string, expiresAt *time.Time) (model.APIToken, string, error) {
+	for _, scope := range scopes {
+		if !slices.Contains(model.AllScopes, scope) {
+			return model.APIToken{}, "", fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return model.APIToken{}, "", errors.New("at least one scope is required")
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return model.APIToken{}, "", err
+	}
+	raw := model.APITokenPrefix + secret
+
+	token := model.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Hint:      raw[len(raw)-4:],
+		TokenHash: hashToken(raw),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+	}
+	if err := database.DB.Create(&token).Error; err != nil {
+		return model.APIToken{}, "", err
+	}
+	return token, raw, nil
+}
+
+// AuthenticateAPIToken resolves a personal API token to its owner and scopes
+func AuthenticateAPIToken(raw string) (model.APIToken, model.User, error) {
+	var token model.APIToken
+	var user model.User
+
+	if err := database.DB.Where("token_hash = ? AND revoked_at IS NULL", hashToken(raw)).First(&token).Error; err != nil {
+		return token, user, ErrInvalidAPIToken
+	}
+	if token.ExpiresAt != nil && time.Now().After(*token.ExpiresAt) {
+		return token, user, ErrInvalidAPIToken
+	}
+	if err := database.DB.First(&user, token.UserID).Error; err != nil {
+		return token, user, ErrInvalidAPIToken
+	}
+
+	// ✅ Record usage at most once per sessionTouchInterval
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > sessionTouchInterval {
+		database.DB.Model(&token).UpdateColumn("last_used_at", time.Now())
+	}
+	return token, user, nil
+}
+
+// TokenScopes splits an API token's stored scopes
+func TokenScopes(token model.APIToken) []string {
+	return strings.Split(token.Scopes, ",")
+}
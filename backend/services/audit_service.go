@@ -0,0 +1,36 @@
+package services
+
+import (
+	"log"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+// Audited actions
+const (
+	AuditAccountLocked       = "account.locked"
+	AuditAccountUnlocked     = "account.unlocked"
+	AuditIPLocked            = "ip.locked"
+	AuditAccountDisabled     = "account.disabled"
+	AuditAccountEnabled      = "account.enabled"
+	AuditRoleChanged         = "account.role_changed"
+	AuditSessionsRevoked     = "account.sessions_revoked"
+	AuditImpersonationStart  = "impersonation.started"
+	AuditImpersonatedRequest = "impersonation.request"
+)
+
+// Audit records a security event. It logs instead of failing the caller, so
+// an audit write problem never turns into a failed request.
+func Audit(action string, actorID, targetUserID *uint, ip, details string) {
+	entry := model.AuditLog{
+		Action:       action,
+		ActorID:      actorID,
+		TargetUserID: targetUserID,
+		IP:           ip,
+		Details:      details,
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		log.Println("❌ Failed to write audit log:", err, action, details)
+	}
+}
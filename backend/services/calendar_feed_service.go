@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+var ErrInvalidCalendarFeed = errors.New("invalid or revoked calendar feed")
+
+// APIURL is this backend's public address, used to build links to it (like
+// calendar feed URLs) that are opened without the frontend
+var APIURL = func() string {
+	if url := os.Getenv("API_URL"); url != "" {
+		return strings.TrimSuffix(url, "/")
+	}
+	return "http://localhost:8080"
+}()
+
+// CreateCalendarFeed stores a new feed for the user and returns it with the
+// plaintext token, which is never retrievable again.
+func CreateCalendarFeed(userID uint, name string, includeCompleted bool) (model.CalendarFeed, string, error) {
+	secret, err := randomToken()
+	if err != nil {
+		return model.CalendarFeed{}, "", err
+	}
+	raw := model.CalendarFeedPrefix + secret
+
+	feed := model.CalendarFeed{
+		UserID:           userID,
+		Name:             name,
+		Hint:             raw[len(raw)-4:],
+		TokenHash:        hashToken(raw),
+		IncludeCompleted: includeCompleted,
+	}
+	if err := database.DB.Create(&feed).Error; err != nil {
+		return model.CalendarFeed{}, "", err
+	}
+	return feed, raw, nil
+}
+
+// CalendarFeedURL is the address calendar apps subscribe to
+func CalendarFeedURL(raw string) string {
+	return APIURL + "/calendar/" + raw + ".ics"
+}
+
+// AuthenticateCalendarFeed resolves a feed token to the feed and its owner
+func AuthenticateCalendarFeed(raw string) (model.CalendarFeed, model.User, error) {
+	var feed model.CalendarFeed
+	var user model.User
+
+	if err := database.DB.Where("token_hash = ? AND revoked_at IS NULL", hashToken(raw)).First(&feed).Error; err != nil {
+		return feed, user, ErrInvalidCalendarFeed
+	}
+	if err := database.DB.First(&user, feed.UserID).Error; err != nil || user.DisabledAt != nil {
+		return feed, user, ErrInvalidCalendarFeed
+	}
+
+	// ✅ Calendar apps poll often, record usage at most once per sessionTouchInterval
+	if feed.LastUsedAt == nil || time.Since(*feed.LastUsedAt) > sessionTouchInterval {
+		database.DB.Model(&feed).UpdateColumn("last_used_at", time.Now())
+	}
+	return feed, user, nil
+}
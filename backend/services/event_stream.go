@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tarun05rawat/go-task-management/database"
+)
+
+// eventChannel is the Postgres NOTIFY channel shared by every backend instance
+const eventChannel = "task_events"
+
+// Postgres rejects NOTIFY payloads of 8000 bytes or more
+const maxNotifyPayload = 7900
+
+// StreamEvent is what connected clients receive on GET /events
+type StreamEvent struct {
+	UserID    uint            `json:"-"`
+	Event     string          `json:"event"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"` // Data was too large for NOTIFY; refetch the task
+	At        time.Time       `json:"at"`
+}
+
+// notifyMessage is the NOTIFY payload (UserID is needed to route the event)
+type notifyMessage struct {
+	UserID uint `json:"user_id"`
+	StreamEvent
+}
+
+// eventHub tracks the clients connected to this instance, keyed by user
+var eventHub = struct {
+	sync.Mutex
+	subscribers map[uint]map[chan StreamEvent]struct{}
+}{subscribers: map[uint]map[chan StreamEvent]struct{}{}}
+
+// SubscribeEvents registers a client for a user's events. Call the returned
+// function when the client disconnects.
+func SubscribeEvents(userID uint) (<-chan StreamEvent, func()) {
+	ch := make(chan StreamEvent, 32)
+
+	eventHub.Lock()
+	if eventHub.subscribers[userID] == nil {
+		eventHub.subscribers[userID] = map[chan StreamEvent]struct{}{}
+	}
+	eventHub.subscribers[userID][ch] = struct{}{}
+	eventHub.Unlock()
+
+	return ch, func() {
+		eventHub.Lock()
+		delete(eventHub.subscribers[userID], ch)
+		if len(eventHub.subscribers[userID]) == 0 {
+			delete(eventHub.subscribers, userID)
+		}
+		eventHub.Unlock()
+	}
+}
+
+// dispatchLocal hands an event to this instance's clients, dropping it for
+// clients too slow to keep up rather than blocking the listener.
+func dispatchLocal(event StreamEvent) {
+	eventHub.Lock()
+	defer eventHub.Unlock()
+
+	for ch := range eventHub.subscribers[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+			log.Println("⚠️ Dropping stream event for slow client of user", event.UserID)
+		}
+	}
+}
+
+// PublishStreamEvent sends the event to every instance through NOTIFY; each
+// instance's listener (including this one) forwards it to its own clients.
+func PublishStreamEvent(userID uint, event string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	msg := notifyMessage{UserID: userID, StreamEvent: StreamEvent{Event: event, Data: raw, At: time.Now()}}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		msg.Data = nil
+		msg.Truncated = true
+		if payload, err = json.Marshal(msg); err != nil {
+			return err
+		}
+	}
+
+	return database.DB.Exec("SELECT pg_notify(?, ?)", eventChannel, string(payload)).Error
+}
+
+// StartEventListener LISTENs for stream events on a dedicated connection,
+// reconnecting with a growing delay when the connection drops.
+func StartEventListener() {
+	go func() {
+		delay := time.Second
+		for {
+			err := listenForEvents(func() { delay = time.Second })
+			log.Println("❌ Event listener disconnected:", err)
+
+			time.Sleep(delay)
+			if delay < time.Minute {
+				delay *= 2
+			}
+		}
+	}()
+}
+
+func listenForEvents(onConnected func()) error {
+	ctx := context.Background()
+
+	conn, err := pgx.Connect(ctx, database.DSN())
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	if _, err := conn.Exec(ctx, "LISTEN "+eventChannel); err != nil {
+		return err
+	}
+	onConnected()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var msg notifyMessage
+		if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+			log.Println("❌ Invalid stream event payload:", err)
+			continue
+		}
+		msg.StreamEvent.UserID = msg.UserID
+		dispatchLocal(msg.StreamEvent)
+	}
+}
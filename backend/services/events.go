@@ -0,0 +1,15 @@
+package services
+
+import "log"
+
+// EmitEvent publishes a task or attachment event to the task owner's
+// webhooks and live event streams. Failures are logged, never returned, so
+// a broken integration can't fail the request that caused the event.
+func EmitEvent(ownerID uint, event string, data any) {
+	if err := EnqueueWebhooks(ownerID, event, data); err != nil {
+		log.Println("❌ Failed to queue webhooks:", err)
+	}
+	if err := PublishStreamEvent(ownerID, event, data); err != nil {
+		log.Println("❌ Failed to publish stream event:", err)
+	}
+}
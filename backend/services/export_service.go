@@ -0,0 +1,310 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ExportTTL           = 7 * 24 * time.Hour // How long a finished export can be downloaded
+	exportPollInterval  = 10 * time.Second
+	exportLease         = 30 * time.Minute // Time a worker has to finish before another may retry
+	exportMaxAttempts   = 3
+	exportRetryInterval = 5 * time.Minute
+)
+
+var ErrExportInProgress = errors.New("an export is already being prepared")
+
+// RequestDataExport queues a new export, unless one is already waiting
+func RequestDataExport(userID uint) (model.DataExport, error) {
+	var export model.DataExport
+	err := database.DB.Where("user_id = ? AND status = ?", userID, "pending").Limit(1).Find(&export).Error
+	if err != nil {
+		return export, err
+	}
+	if export.ID != 0 {
+		return export, ErrExportInProgress
+	}
+
+	export = model.DataExport{UserID: userID, Status: "pending", NextAttemptAt: time.Now()}
+	return export, database.DB.Create(&export).Error
+}
+
+// StartExportWorker builds queued exports and deletes expired ones in the background
+func StartExportWorker() {
+	go func() {
+		for {
+			if err := processExportBatch(); err != nil {
+				log.Println("❌ Export worker error:", err)
+			}
+			if err := expireExports(); err != nil {
+				log.Println("❌ Export cleanup error:", err)
+			}
+			time.Sleep(exportPollInterval)
+		}
+	}()
+}
+
+// processExportBatch claims one due export with a lease (so other instances
+// skip it) and builds it without holding any row locks
+func processExportBatch() error {
+	var export model.DataExport
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+			Order("id").Limit(1).
+			Find(&export).Error
+		if err != nil || export.ID == 0 {
+			return err
+		}
+
+		// ✅ Count the attempt as it starts, so a worker dying mid-build still uses one up
+		export.Attempts++
+		return tx.Model(&export).Updates(map[string]any{
+			"attempts":        export.Attempts,
+			"next_attempt_at": time.Now().Add(exportLease),
+		}).Error
+	})
+	if err != nil || export.ID == 0 {
+		return err
+	}
+
+	var user model.User
+	if err = database.DB.First(&user, export.UserID).Error; err == nil {
+		err = buildExport(user, &export)
+	}
+
+	updates := map[string]any{}
+	if err != nil {
+		updates["last_error"] = err.Error()
+		if export.Attempts >= exportMaxAttempts {
+			updates["status"] = "failed"
+		} else {
+			updates["next_attempt_at"] = time.Now().Add(exportRetryInterval)
+		}
+	} else {
+		now := time.Now()
+		updates["status"] = "ready"
+		updates["completed_at"] = now
+		updates["expires_at"] = now.Add(ExportTTL)
+		updates["file_key"] = export.FileKey
+		updates["size_bytes"] = export.SizeBytes
+		updates["last_error"] = ""
+	}
+
+	// ✅ Updates rather than Save, which would re-insert an export deleted with its account meanwhile
+	result := database.DB.Model(&model.DataExport{}).Where("id = ? AND status = ?", export.ID, "pending").Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Deleted, or finished by another worker after our lease ran out: our ZIP isn't needed
+		if export.FileKey != "" {
+			DeleteFromS3(export.FileKey)
+		}
+		return nil
+	}
+
+	if err == nil {
+		notifyExportReady(user, export)
+	}
+	return nil
+}
+
+// buildExport writes the user's data to a ZIP and uploads it to S3
+func buildExport(user model.User, export *model.DataExport) error {
+	tmp, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archive := zip.NewWriter(tmp)
+	if err := writeExportData(archive, user); err != nil {
+		return err
+	}
+	if err := writeExportFiles(archive, user); err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	suffix, err := randomHex(8)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%sexport-%d-%s.zip", UserExportPrefix(user.ID), export.ID, suffix)
+	if err := UploadToS3(tmp, "application/zip", key); err != nil {
+		return err
+	}
+
+	export.FileKey = key
+	export.SizeBytes = size
+	return nil
+}
+
+// writeExportData adds one JSON file per kind of record stored about the user
+func writeExportData(archive *zip.Writer, user model.User) error {
+	var (
+		tasks         []model.Task
+		watching      []model.TaskWatcher
+		notifications []model.Notification
+		views         []model.SavedView
+		webhooks      []model.Webhook
+		sessions      []model.Session
+		apiTokens     []model.APIToken
+		calendarFeeds []model.CalendarFeed
+		identities    []model.UserIdentity
+		auditLog      []model.AuditLog
+		userData      []model.UserData
+	)
+	queries := []struct {
+		dest  any
+		query string
+		args  []any
+	}{
+		{&tasks, "user_id = ?", []any{user.ID}},
+		{&watching, "user_id = ?", []any{user.ID}},
+		{&notifications, "user_id = ?", []any{user.ID}},
+		{&views, "user_id = ?", []any{user.ID}},
+		{&webhooks, "user_id = ?", []any{user.ID}},
+		{&sessions, "user_id = ?", []any{user.ID}},
+		{&apiTokens, "user_id = ?", []any{user.ID}},
+		{&calendarFeeds, "user_id = ?", []any{user.ID}},
+		{&identities, "user_id = ?", []any{user.ID}},
+		{&auditLog, "actor_id = ? OR target_user_id = ?", []any{user.ID, user.ID}},
+		{&userData, "user_id = ?", []any{user.ID}},
+	}
+	for _, q := range queries {
+		if err := database.DB.Where(q.query, q.args...).Order("created_at").Find(q.dest).Error; err != nil {
+			return err
+		}
+	}
+
+	// UserData rows hold free-form JSON, keep it as JSON rather than a quoted string
+	data := make([]map[string]any, 0, len(userData))
+	for _, row := range userData {
+		var value any = row.Data
+		if json.Valid([]byte(row.Data)) {
+			value = json.RawMessage(row.Data)
+		}
+		data = append(data, map[string]any{"id": row.ID, "created_at": row.CreatedAt, "updated_at": row.UpdatedAt, "data": value})
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", model.NewUserResponse(user)},
+		{"tasks.json", tasks},
+		{"watching.json", watching},
+		{"notifications.json", notifications},
+		{"notification_preferences.json", PreferencesFor(user.ID)},
+		{"saved_views.json", views},
+		{"webhooks.json", webhooks},
+		{"sessions.json", sessions},
+		{"api_tokens.json", apiTokens},
+		{"calendar_feeds.json", calendarFeeds},
+		{"sso_identities.json", identities},
+		{"activity.json", auditLog},
+		{"user_data.json", data},
+	}
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExportFiles copies the user's uploads (avatar, task attachments) into files/
+func writeExportFiles(archive *zip.Writer, user model.User) error {
+	keys, err := ListS3Keys(UserFilesPrefix(user.ID))
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		// ✅ Don't nest earlier exports inside new ones
+		if strings.HasPrefix(key, UserExportPrefix(user.ID)) {
+			continue
+		}
+
+		body, err := DownloadFromS3(key)
+		if err != nil {
+			return err
+		}
+		w, err := archive.Create("files/" + strings.TrimPrefix(key, UserFilesPrefix(user.ID)))
+		if err == nil {
+			_, err = io.Copy(w, body)
+		}
+		body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyExportReady tells the user in-app and by email
+func notifyExportReady(user model.User, export model.DataExport) {
+	message := "Your data export is ready to download"
+	if err := database.DB.Create(&model.Notification{UserID: user.ID, Event: EventExportReady, Message: message}).Error; err != nil {
+		log.Println("❌ Failed to create export notification:", err)
+	}
+
+	err := QueueEmail(user.Email, TemplateExportReady, map[string]any{
+		"Username":  user.Username,
+		"ExportURL": fmt.Sprintf("%s/account/export?id=%d", FrontendURL, export.ID),
+		"ExpiresIn": "7 days",
+	})
+	if err != nil {
+		log.Println("❌ Failed to queue export email:", err)
+	}
+}
+
+// expireExports deletes the ZIPs of exports past their download window
+func expireExports() error {
+	var exports []model.DataExport
+	err := database.DB.Where("status = ? AND expires_at < ?", "ready", time.Now()).Limit(50).Find(&exports).Error
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if err := DeleteFromS3(export.FileKey); err != nil {
+			log.Println("❌ Failed to delete expired export:", err)
+			continue
+		}
+		database.DB.Model(&export).Updates(map[string]any{"status": "expired", "file_key": ""})
+	}
+	return nil
+}
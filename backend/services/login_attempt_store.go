@@ -0,0 +1,135 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttemptState is the failed-login history of one account or IP
+type AttemptState struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// LoginAttemptStore keeps AttemptStates. Use MemoryAttemptStore for a
+// single instance and PostgresAttemptStore when several instances share load.
+type LoginAttemptStore interface {
+	Get(key string) (AttemptState, error)
+	// RecordFailure counts a failure, starting over if the previous one is older than window
+	RecordFailure(key string, now time.Time, window time.Duration) (AttemptState, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+// memoryPruneMin is how many keys MemoryAttemptStore holds before it first
+// looks for stale ones
+const memoryPruneMin = 1024
+
+// MemoryAttemptStore keeps attempts in process memory. Keys whose last
+// failure is older than the window and that aren't locked are dropped as the
+// map grows, so made-up emails or rotating IPs can't fill it up.
+type MemoryAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]AttemptState
+	pruneAt  int // Map size that triggers the next prune
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: map[string]AttemptState{}, pruneAt: memoryPruneMin}
+}
+
+// prune drops the keys that no longer affect logins. Called with the lock held;
+// the next prune waits until the map doubles, so the cost per failure stays constant.
+func (s *MemoryAttemptStore) prune(now time.Time, window time.Duration) {
+	for key, state := range s.attempts {
+		if now.Sub(state.LastFailureAt) > window && !now.Before(state.LockedUntil) {
+			delete(s.attempts, key)
+		}
+	}
+	s.pruneAt = max(2*len(s.attempts), memoryPruneMin)
+}
+
+func (s *MemoryAttemptStore) Get(key string) (AttemptState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[key], nil
+}
+
+func (s *MemoryAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (AttemptState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.attempts[key]
+	if now.Sub(state.LastFailureAt) > window {
+		state.Failures = 0
+	}
+	state.Failures++
+	state.LastFailureAt = now
+	s.attempts[key] = state
+
+	if len(s.attempts) >= s.pruneAt {
+		s.prune(now, window)
+	}
+	return state, nil
+}
+
+func (s *MemoryAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.attempts[key]
+	state.LockedUntil = until
+	s.attempts[key] = state
+	return nil
+}
+
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+// PostgresAttemptStore keeps attempts in the login_attempts table
+type PostgresAttemptStore struct{}
+
+func (PostgresAttemptStore) Get(key string) (AttemptState, error) {
+	var attempt model.LoginAttempt
+	err := database.DB.Where("key = ?", key).Limit(1).Find(&attempt).Error
+	return AttemptState{Failures: attempt.Failures, LastFailureAt: attempt.LastFailureAt, LockedUntil: attempt.LockedUntil}, err
+}
+
+func (PostgresAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (AttemptState, error) {
+	var attempt model.LoginAttempt
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// ✅ Make sure the row exists, then lock it so concurrent failures are all counted
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.LoginAttempt{Key: key}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&attempt).Error; err != nil {
+			return err
+		}
+
+		if now.Sub(attempt.LastFailureAt) > window {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailureAt = now
+		return tx.Save(&attempt).Error
+	})
+	return AttemptState{Failures: attempt.Failures, LastFailureAt: attempt.LastFailureAt, LockedUntil: attempt.LockedUntil}, err
+}
+
+func (PostgresAttemptStore) Lock(key string, until time.Time) error {
+	return database.DB.Model(&model.LoginAttempt{}).Where("key = ?", key).Update("locked_until", until).Error
+}
+
+func (PostgresAttemptStore) Reset(key string) error {
+	return database.DB.Where("key = ?", key).Delete(&model.LoginAttempt{}).Error
+}
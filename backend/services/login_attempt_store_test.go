@@ -0,0 +1,36 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryAttemptStorePrunesStaleKeys(t *testing.T) {
+	store := NewMemoryAttemptStore()
+	window := 15 * time.Minute
+	now := time.Now()
+
+	// Junk from an hour ago, one key still locked and one failing within the window
+	for i := 0; i < memoryPruneMin-3; i++ {
+		store.RecordFailure(fmt.Sprintf("ip:10.0.%d.%d", i/256, i%256), now.Add(-time.Hour), window)
+	}
+	store.RecordFailure("account:locked@example.com", now.Add(-time.Hour), window)
+	store.Lock("account:locked@example.com", now.Add(time.Hour))
+	store.RecordFailure("account:recent@example.com", now.Add(-time.Minute), window)
+
+	// ✅ The failure that fills the map sweeps out the stale keys
+	store.RecordFailure("account:new@example.com", now, window)
+
+	if len(store.attempts) != 3 {
+		t.Fatalf("%d keys after pruning, want 3", len(store.attempts))
+	}
+	for _, key := range []string{"account:locked@example.com", "account:recent@example.com", "account:new@example.com"} {
+		if state, _ := store.Get(key); state.Failures != 1 {
+			t.Errorf("%s: %d failures, want 1", key, state.Failures)
+		}
+	}
+	if store.pruneAt != memoryPruneMin {
+		t.Errorf("next prune at %d keys, want %d", store.pruneAt, memoryPruneMin)
+	}
+}
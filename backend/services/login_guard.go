@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLoginLocked means too many failed attempts; RetryAfter says when to try again
+type ErrLoginLocked struct {
+	RetryAfter time.Duration
+}
+
+func (e *ErrLoginLocked) Error() string {
+	return "too many failed login attempts, try again later"
+}
+
+// IsLoginLocked unwraps an ErrLoginLocked
+func IsLoginLocked(err error) (*ErrLoginLocked, bool) {
+	var locked *ErrLoginLocked
+	ok := errors.As(err, &locked)
+	return locked, ok
+}
+
+// Lockout settings, overridable via env
+var (
+	loginMaxAccountFailures = envInt("LOGIN_MAX_FAILURES", 5)
+	loginMaxIPFailures      = envInt("LOGIN_MAX_IP_FAILURES", 50)
+	loginFailureWindow      = envDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute)
+	loginLockoutDuration    = envDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+)
+
+// Progressive delay: after the first failure each retry must wait twice as long, up to a cap
+const (
+	loginBaseDelay = time.Second
+	loginMaxDelay  = 30 * time.Second
+)
+
+// LoginAttempts is where failed logins are counted (see InitLoginGuard)
+var LoginAttempts LoginAttemptStore = NewMemoryAttemptStore()
+
+// InitLoginGuard picks the attempt store (LOGIN_ATTEMPT_STORE=postgres|memory)
+func InitLoginGuard() {
+	if os.Getenv("LOGIN_ATTEMPT_STORE") == "memory" {
+		LoginAttempts = NewMemoryAttemptStore()
+		log.Println("✅ Tracking login attempts in memory")
+		return
+	}
+
+	LoginAttempts = PostgresAttemptStore{}
+	log.Println("✅ Tracking login attempts in Postgres")
+}
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// CheckLoginAllowed returns an ErrLoginLocked if the account or IP is locked
+// out, or the account hasn't waited out its progressive delay yet
+func CheckLoginAllowed(email, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+
+	account, err := LoginAttempts.Get(accountAttemptKey(email))
+	if err != nil {
+		return err
+	}
+	if account.LockedUntil.After(now) {
+		wait = account.LockedUntil.Sub(now)
+	} else if now.Sub(account.LastFailureAt) <= loginFailureWindow {
+		if ready := account.LastFailureAt.Add(loginDelay(account.Failures)); ready.After(now) {
+			wait = ready.Sub(now)
+		}
+	}
+
+	// ✅ IPs only get the hard lockout; delaying every retry would punish shared NATs
+	addr, err := LoginAttempts.Get(ipAttemptKey(ip))
+	if err != nil {
+		return err
+	}
+	if addr.LockedUntil.After(now) {
+		wait = max(wait, addr.LockedUntil.Sub(now))
+	}
+
+	if wait > 0 {
+		return &ErrLoginLocked{RetryAfter: wait}
+	}
+	return nil
+}
+
+// RecordLoginFailure counts a failed attempt against the account and IP,
+// locking either one out once it reaches its limit. userID is 0 for unknown emails.
+func RecordLoginFailure(email, ip string, userID uint) error {
+	now := time.Now()
+
+	account, err := LoginAttempts.RecordFailure(accountAttemptKey(email), now, loginFailureWindow)
+	if err != nil {
+		return err
+	}
+	if account.Failures >= loginMaxAccountFailures && !account.LockedUntil.After(now) {
+		if err := LoginAttempts.Lock(accountAttemptKey(email), now.Add(loginLockoutDuration)); err != nil {
+			return err
+		}
+
+		var target *uint
+		if userID != 0 {
+			target = &userID
+		}
+		Audit(AuditAccountLocked, nil, target, ip,
+			fmt.Sprintf("%s locked for %s after %d failed logins", email, loginLockoutDuration, account.Failures))
+	}
+
+	addr, err := LoginAttempts.RecordFailure(ipAttemptKey(ip), now, loginFailureWindow)
+	if err != nil {
+		return err
+	}
+	if addr.Failures >= loginMaxIPFailures && !addr.LockedUntil.After(now) {
+		if err := LoginAttempts.Lock(ipAttemptKey(ip), now.Add(loginLockoutDuration)); err != nil {
+			return err
+		}
+		Audit(AuditIPLocked, nil, nil, ip,
+			fmt.Sprintf("IP locked for %s after %d failed logins", loginLockoutDuration, addr.Failures))
+	}
+	return nil
+}
+
+// RecordLoginSuccess clears the account's failures. The IP's are kept so one
+// valid account can't be used to reset the counter while guessing others.
+func RecordLoginSuccess(email string) error {
+	return LoginAttempts.Reset(accountAttemptKey(email))
+}
+
+// UnlockAccount clears an account's lockout and failure count
+func UnlockAccount(email string) error {
+	return LoginAttempts.Reset(accountAttemptKey(email))
+}
+
+// loginDelay is how long to wait after the nth consecutive failure
+func loginDelay(failures int) time.Duration {
+	if failures <= 1 {
+		return 0
+	}
+	delay := loginBaseDelay << min(failures-2, 10)
+	return min(delay, loginMaxDelay)
+}
+
+func envInt(name string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
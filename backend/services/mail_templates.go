@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Email templates available to QueueEmail
+const (
+	TemplateTaskAssigned  = "task_assigned"
+	TemplateTasksAssigned = "tasks_assigned" // Several tasks assigned at once
+	TemplateMention       = "mention"
+	TemplateDueSoon       = "due_soon"
+	TemplatePasswordReset = "password_reset"
+	TemplateTaskUpdate    = "task_update"
+	TemplateVerifyEmail   = "verify_email"
+	TemplateEmailChanged  = "email_changed" // Sent to the old address
+	TemplateExportReady   = "export_ready"
+)
+
+// Each template's first line is the subject, the rest is the body
+var mailTemplates = template.Must(template.New("mail").Parse(`
+{{define "task_assigned"}}You were assigned "{{.TaskTitle}}"
+Hi {{.Username}},
+
+{{.ActorName}} assigned you the task "{{.TaskTitle}}".
+
+Open it at {{.TaskURL}}
+{{end}}
+
+{{define "tasks_assigned"}}You were assigned {{.Count}} tasks
+Hi {{.Username}},
+
+{{.ActorName}} assigned you {{.Count}} tasks:
+{{range .Tasks}}
+- "{{.Title}}": {{.URL}}{{end}}{{if .More}}
+- and {{.More}} more{{end}}
+{{end}}
+
+{{define "mention"}}{{.ActorName}} mentioned you on "{{.TaskTitle}}"
+Hi {{.Username}},
+
+{{.ActorName}} mentioned you:
+
+    {{.Excerpt}}
+
+Open it at {{.TaskURL}}
+{{end}}
+
+{{define "due_soon"}}"{{.TaskTitle}}" is due {{.DueDate}}
+Hi {{.Username}},
+
+Your task "{{.TaskTitle}}" is due {{.DueDate}} and is still {{.Status}}.
+
+Open it at {{.TaskURL}}
+{{end}}
+
+{{define "password_reset"}}Reset your password
+Hi {{.Username}},
+
+Someone asked to reset the password for your account. If it was you, open
+the link below within {{.ExpiresIn}}:
+
+{{.ResetURL}}
+
+If you didn't ask for this you can ignore this email.
+{{end}}
+
+{{define "verify_email"}}Confirm your email address
+Hi {{.Username}},
+
+Please confirm this is your email address by opening the link below within
+{{.ExpiresIn}}:
+
+{{.VerifyURL}}
+{{end}}
+
+{{define "email_changed"}}Your email address was changed
+Hi {{.Username}},
+
+The email address of your account was changed to {{.NewEmail}}. Links we
+sent to this address before, like password resets, no longer work.
+
+If you didn't make this change, contact support right away.
+{{end}}
+
+{{define "export_ready"}}Your data export is ready
+Hi {{.Username}},
+
+The export of your account data you asked for is ready. Download it from
+your account settings within {{.ExpiresIn}}:
+
+{{.ExportURL}}
+{{end}}
+
+{{define "task_update"}}Update on "{{.TaskTitle}}"
+Hi {{.Username}},
+
+{{.Message}}
+
+Open it at {{.TaskURL}}
+{{end}}
+`))
+
+// RenderEmail executes a named template and splits it into subject and body
+func RenderEmail(name string, data any) (subject, body string, err error) {
+	var b strings.Builder
+	if err := mailTemplates.ExecuteTemplate(&b, name, data); err != nil {
+		return "", "", fmt.Errorf("render %s email: %w", name, err)
+	}
+
+	subject, body, _ = strings.Cut(b.String(), "\n")
+	return strings.TrimSpace(subject), strings.TrimLeft(body, "\n"), nil
+}
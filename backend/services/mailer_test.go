@@ -0,0 +1,43 @@
+package services
+
+import (
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestFormatMessageSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"Reset your password", "Reset your password"},
+		{"You were assigned \"Fix\rBcc: victim@example.com\"", "You were assigned \"Fix Bcc: victim@example.com\""},
+		{"Line\r\nBreak", "Line  Break"},
+		{"Überprüfung für „Aufgabe“", "Überprüfung für „Aufgabe“"},
+	}
+
+	for _, tt := range tests {
+		raw := formatMessage("no-reply@localhost", Email{To: "someone@example.com", Subject: tt.subject, Body: "Hi"})
+		msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+		if err != nil {
+			t.Fatalf("%q: %v", tt.subject, err)
+		}
+		if len(msg.Header["Bcc"]) != 0 {
+			t.Errorf("%q: subject injected a Bcc header", tt.subject)
+		}
+
+		header := msg.Header.Get("Subject")
+		for _, r := range header {
+			if r > 127 {
+				t.Errorf("%q: header %q isn't ASCII", tt.subject, header)
+				break
+			}
+		}
+		got, err := new(mime.WordDecoder).DecodeHeader(header)
+		if err != nil || got != tt.want {
+			t.Errorf("%q: decoded subject = %q (%v), want %q", tt.subject, got, err, tt.want)
+		}
+	}
+}
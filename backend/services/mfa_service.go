@@ -0,0 +1,172 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication is not set up")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+)
+
+// MFAIssuer is the account issuer shown in authenticator apps
+func MFAIssuer() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Task Management"
+}
+
+// RequireAdminMFA makes admin permissions depend on TOTP when REQUIRE_ADMIN_MFA=true
+func RequireAdminMFA() bool {
+	return os.Getenv("REQUIRE_ADMIN_MFA") == "true"
+}
+
+// HasTOTP reports whether the user has confirmed a TOTP authenticator
+func HasTOTP(userID uint) bool {
+	var count int64
+	database.DB.Model(&model.TOTPCredential{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL", userID).
+		Count(&count)
+	return count > 0
+}
+
+// StartTOTPEnrollment stores a new unconfirmed secret and returns it with its provisioning URI
+func StartTOTPEnrollment(user model.User) (string, string, error) {
+	if HasTOTP(user.ID) {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	credential := model.TOTPCredential{UserID: user.ID, Secret: secret}
+	if err := database.DB.Save(&credential).Error; err != nil {
+		return "", "", err
+	}
+	return secret, TOTPProvisioningURI(MFAIssuer(), user.Email, secret), nil
+}
+
+// ConfirmTOTPEnrollment turns on TOTP once the user proves their app
+// produces valid codes, and returns a fresh set of recovery codes.
+func ConfirmTOTPEnrollment(userID uint, code string) ([]string, error) {
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var credential model.TOTPCredential
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&credential, "user_id = ?", userID).Error; err != nil {
+			return ErrMFANotEnrolled
+		}
+		if credential.ConfirmedAt != nil {
+			return ErrMFAAlreadyEnabled
+		}
+
+		step, ok := ValidateTOTP(credential.Secret, code, credential.LastUsedStep)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+
+		now := time.Now()
+		credential.ConfirmedAt = &now
+		credential.LastUsedStep = step
+		if err := tx.Save(&credential).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// VerifySecondFactor accepts either a current TOTP code or an unused recovery code
+func VerifySecondFactor(userID uint, code string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var credential model.TOTPCredential
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND confirmed_at IS NOT NULL", userID).
+			First(&credential).Error
+		if err != nil {
+			return ErrMFANotEnrolled
+		}
+
+		if step, ok := ValidateTOTP(credential.Secret, code, credential.LastUsedStep); ok {
+			return tx.Model(&credential).Update("last_used_step", step).Error
+		}
+
+		// ✅ Fall back to a recovery code (each works once)
+		result := tx.Model(&model.RecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashToken(normalizeRecoveryCode(code))).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidMFACode
+		}
+		return nil
+	})
+}
+
+// DisableTOTP removes the authenticator and all recovery codes
+func DisableTOTP(userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.TOTPCredential{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes invalidates the old recovery codes and returns new ones
+func RegenerateRecoveryCodes(userID uint) ([]string, error) {
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]model.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		rows[i] = model.RecoveryCode{UserID: userID, CodeHash: hashToken(normalizeRecoveryCode(codes[i]))}
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes the user may type differently
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
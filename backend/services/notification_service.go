@@ -0,0 +1,288 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Task events a watcher can subscribe to
+const (
+	EventStatusChange     = "status_change"
+	EventNewComment       = "new_comment"
+	EventAttachmentUpload = "attachment_upload"
+	EventDueDate          = "due_date"
+	EventExportReady      = "export_ready" // Not a task event, always delivered
+	EventAssigned         = "assigned"     // Always delivered to the assignee
+	EventWatchInvite      = "watch_invite" // Always delivered, in-app only
+)
+
+// Reasons a user ends up watching a task
+const (
+	WatchReasonCreator   = "creator"
+	WatchReasonManual    = "manual"
+	WatchReasonAssignee  = "assignee"
+	WatchReasonCommenter = "commenter"
+)
+
+// WatchTask subscribes a user to a task. Watching twice is a no-op.
+func WatchTask(ownerID, taskID, userID uint, reason string) error {
+	watcher := model.TaskWatcher{
+		OwnerID: ownerID,
+		TaskID:  taskID,
+		UserID:  userID,
+		Reason:  reason,
+	}
+	return database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&watcher).Error
+}
+
+// UnwatchTask removes a user's subscription to a task, along with the
+// invitation they accepted to watch it
+func UnwatchTask(ownerID, taskID, userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("owner_id = ? AND task_id = ? AND user_id = ?", ownerID, taskID, userID).
+			Delete(&model.TaskWatcher{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("owner_id = ? AND task_id = ? AND user_id = ?", ownerID, taskID, userID).
+			Delete(&model.WatchInvite{}).Error
+	})
+}
+
+// ErrWatchInviteNotFound is returned for invitations that don't exist, aren't
+// the caller's, or whose task is gone
+var ErrWatchInviteNotFound = errors.New("invitation not found")
+
+// InviteWatcher asks a user to watch the owner's task. Nothing is sent by email:
+// the invitee only sees an in-app notification and nothing changes until they accept.
+// Unknown users and repeated invitations are silently ignored, so the caller learns
+// nothing about which user IDs exist.
+func InviteWatcher(task model.Task, owner model.User, inviteeID uint) error {
+	var invitee model.User
+	if err := database.DB.Where("id = ?", inviteeID).Limit(1).Find(&invitee).Error; err != nil {
+		return err
+	}
+	if invitee.ID == 0 {
+		return nil
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		invite := model.WatchInvite{OwnerID: task.UserID, TaskID: task.TaskID, UserID: invitee.ID}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&invite)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Create(&model.Notification{
+			UserID:  invitee.ID,
+			OwnerID: task.UserID,
+			TaskID:  task.TaskID,
+			ActorID: owner.ID,
+			Event:   EventWatchInvite,
+			Message: fmt.Sprintf("%s invited you to watch %q", owner.Username, task.Title),
+		}).Error
+	})
+}
+
+// PendingWatchInvites lists the invitations the user hasn't answered yet
+func PendingWatchInvites(userID uint) ([]model.WatchInvite, error) {
+	var invites []model.WatchInvite
+	err := database.DB.Where("user_id = ? AND accepted_at IS NULL", userID).Order("created_at DESC").Find(&invites).Error
+	if err != nil {
+		return nil, err
+	}
+	for i, invite := range invites {
+		var task model.Task
+		database.DB.Where("user_id = ? AND task_id = ?", invite.OwnerID, invite.TaskID).Limit(1).Find(&task)
+		invites[i].TaskTitle = task.Title
+	}
+	return invites, nil
+}
+
+// AcceptWatchInvite starts watching the invited task
+func AcceptWatchInvite(inviteID, userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var invite model.WatchInvite
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", inviteID, userID).First(&invite).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWatchInviteNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var tasks int64
+		tx.Model(&model.Task{}).Where("user_id = ? AND task_id = ?", invite.OwnerID, invite.TaskID).Count(&tasks)
+		if tasks == 0 {
+			return ErrWatchInviteNotFound
+		}
+
+		if invite.AcceptedAt == nil {
+			now := time.Now()
+			if err := tx.Model(&invite).Update("accepted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		watcher := model.TaskWatcher{OwnerID: invite.OwnerID, TaskID: invite.TaskID, UserID: userID, Reason: WatchReasonManual}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&watcher).Error
+	})
+}
+
+// DeclineWatchInvite drops an invitation the user hasn't accepted
+func DeclineWatchInvite(inviteID, userID uint) error {
+	result := database.DB.Where("id = ? AND user_id = ? AND accepted_at IS NULL", inviteID, userID).Delete(&model.WatchInvite{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWatchInviteNotFound
+	}
+	return nil
+}
+
+// IsCollaborator reports whether the user may be assigned the owner's tasks:
+// the owner themselves, or someone who accepted an invitation to watch one of
+// their tasks. Watcher rows alone don't count, the owner creates those.
+func IsCollaborator(ownerID, userID uint) bool {
+	if ownerID == userID {
+		return true
+	}
+	var count int64
+	database.DB.Model(&model.WatchInvite{}).
+		Where("owner_id = ? AND user_id = ? AND accepted_at IS NOT NULL", ownerID, userID).
+		Limit(1).Count(&count)
+	return count > 0
+}
+
+// PreferencesFor returns the user's saved preferences, or the defaults if none are saved
+func PreferencesFor(userID uint) model.NotificationPreference {
+	pref := model.DefaultNotificationPreference(userID)
+	database.DB.Where("user_id = ?", userID).Limit(1).Find(&pref)
+	return pref
+}
+
+// wants reports whether the preference allows the given event
+func wants(pref model.NotificationPreference, event string) bool {
+	switch event {
+	case EventStatusChange:
+		return pref.StatusChange
+	case EventNewComment:
+		return pref.NewComment
+	case EventAttachmentUpload:
+		return pref.AttachmentUpload
+	case EventDueDate:
+		return pref.DueDate
+	}
+	return false
+}
+
+// NotifyWatchers records a notification for every watcher of the task whose
+// preferences allow the event. The actor is never notified of their own change.
+func NotifyWatchers(ownerID, taskID, actorID uint, event, message string) error {
+	var watchers []model.TaskWatcher
+	if err := database.DB.Where("owner_id = ? AND task_id = ?", ownerID, taskID).Find(&watchers).Error; err != nil {
+		return err
+	}
+
+	var task model.Task
+	database.DB.Where("user_id = ? AND task_id = ?", ownerID, taskID).Limit(1).Find(&task)
+
+	var notifications []model.Notification
+	for _, w := range watchers {
+		if w.UserID == actorID || !wants(PreferencesFor(w.UserID), event) {
+			continue
+		}
+
+		// ✅ Email goes through the outbox, so this never waits on the mail server
+		var watcher model.User
+		if err := database.DB.First(&watcher, w.UserID).Error; err == nil {
+			err := QueueEmail(watcher.Email, TemplateTaskUpdate, map[string]any{
+				"Username":  watcher.Username,
+				"TaskTitle": task.Title,
+				"Message":   message,
+				"TaskURL":   TaskURL(ownerID, taskID),
+			})
+			if err != nil {
+				log.Println("❌ Failed to queue notification email:", err)
+			}
+		}
+
+		notifications = append(notifications, model.Notification{
+			UserID:  w.UserID,
+			OwnerID: ownerID,
+			TaskID:  taskID,
+			ActorID: actorID,
+			Event:   event,
+			Message: message,
+		})
+	}
+
+	if len(notifications) == 0 {
+		return nil
+	}
+	return database.DB.Create(&notifications).Error
+}
+
+// maxAssignedInEmail caps how many task titles one assignment email lists
+const maxAssignedInEmail = 10
+
+// NotifyAssigned tells a user they were assigned tasks: in-app for each task,
+// and in one email however many tasks there are. Assigning yourself doesn't notify.
+func NotifyAssigned(tasks []model.Task, actor model.User, assigneeID uint) {
+	if assigneeID == actor.ID || len(tasks) == 0 {
+		return
+	}
+
+	var assignee model.User
+	if err := database.DB.First(&assignee, assigneeID).Error; err != nil {
+		return
+	}
+
+	notifications := make([]model.Notification, 0, len(tasks))
+	for _, task := range tasks {
+		notifications = append(notifications, model.Notification{
+			UserID:  assignee.ID,
+			OwnerID: task.UserID,
+			TaskID:  task.TaskID,
+			ActorID: actor.ID,
+			Event:   EventAssigned,
+			Message: fmt.Sprintf("%s assigned you %q", actor.Username, task.Title),
+		})
+	}
+	if err := database.DB.CreateInBatches(&notifications, 100).Error; err != nil {
+		log.Println("❌ Failed to create assignment notifications:", err)
+	}
+
+	var err error
+	if len(tasks) == 1 {
+		err = QueueEmail(assignee.Email, TemplateTaskAssigned, map[string]any{
+			"Username":  assignee.Username,
+			"ActorName": actor.Username,
+			"TaskTitle": tasks[0].Title,
+			"TaskURL":   TaskURL(tasks[0].UserID, tasks[0].TaskID),
+		})
+	} else {
+		listed := tasks[:min(len(tasks), maxAssignedInEmail)]
+		items := make([]map[string]string, 0, len(listed))
+		for _, task := range listed {
+			items = append(items, map[string]string{"Title": task.Title, "URL": TaskURL(task.UserID, task.TaskID)})
+		}
+		err = QueueEmail(assignee.Email, TemplateTasksAssigned, map[string]any{
+			"Username":  assignee.Username,
+			"ActorName": actor.Username,
+			"Count":     len(tasks),
+			"Tasks":     items,
+			"More":      len(tasks) - len(listed),
+		})
+	}
+	if err != nil {
+		log.Println("❌ Failed to queue assignment email:", err)
+	}
+}
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcDiscoveryTTL = time.Hour
+	oidcFlowTTL      = 10 * time.Minute
+)
+
+var ErrOIDCNotConfigured = errors.New("OIDC is not configured")
+
+// OIDCConfig is read from OIDC_* environment variables
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCFlow is the per-login state kept in a signed cookie between the
+// redirect to the provider and the callback
+type OIDCFlow struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// OIDCIdentity is the verified identity taken from an ID token
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcProvider caches the discovery document and signing keys
+var oidcProvider = struct {
+	sync.Mutex
+	discovery oidcDiscovery
+	fetchedAt time.Time
+	keys      map[string]crypto.PublicKey
+}{}
+
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// LoadOIDCConfig returns the provider settings, or ErrOIDCNotConfigured
+func LoadOIDCConfig() (OIDCConfig, error) {
+	cfg := OIDCConfig{
+		Issuer:       strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+	}
+	if cfg.Issuer == "" || cfg.ClientID == "" {
+		return cfg, ErrOIDCNotConfigured
+	}
+	if cfg.RedirectURL == "" {
+		cfg.RedirectURL = "http://localhost:8080/auth/oidc/callback"
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return cfg, nil
+}
+
+// OIDCAuthURL starts a login: it returns the provider URL to redirect to and
+// the signed flow state to keep in a cookie until the callback.
+func OIDCAuthURL(cfg OIDCConfig) (string, string, error) {
+	discovery, err := discoverOIDC(cfg.Issuer)
+	if err != nil {
+		return "", "", err
+	}
+
+	var flow OIDCFlow
+	for _, target := range []*string{&flow.State, &flow.Nonce, &flow.CodeVerifier} {
+		if *target, err = randomToken(); err != nil {
+			return "", "", err
+		}
+	}
+
+	// ✅ PKCE (RFC 7636) with the S256 method
+	challenge := sha256.Sum256([]byte(flow.CodeVerifier))
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", cfg.ClientID)
+	query.Set("redirect_uri", cfg.RedirectURL)
+	query.Set("scope", strings.Join(cfg.Scopes, " "))
+	query.Set("state", flow.State)
+	query.Set("nonce", flow.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	signedFlow, err := signJWT(tokenTypeOIDCFlow, jwt.MapClaims{
+		"state":         flow.State,
+		"nonce":         flow.Nonce,
+		"code_verifier": flow.CodeVerifier,
+		"exp":           time.Now().Add(oidcFlowTTL).Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), signedFlow, nil
+}
+
+// ParseOIDCFlow verifies the flow cookie set by OIDCAuthURL
+func ParseOIDCFlow(signedFlow string) (OIDCFlow, error) {
+	claims, err := parseToken(signedFlow, tokenTypeOIDCFlow)
+	if err != nil {
+		return OIDCFlow{}, errors.New("login attempt expired, please try again")
+	}
+
+	flow := OIDCFlow{}
+	flow.State, _ = claims["state"].(string)
+	flow.Nonce, _ = claims["nonce"].(string)
+	flow.CodeVerifier, _ = claims["code_verifier"].(string)
+	if flow.State == "" {
+		return OIDCFlow{}, errors.New("invalid login state")
+	}
+	return flow, nil
+}
+
+// ExchangeOIDCCode redeems the authorization code and verifies the returned ID token
+func ExchangeOIDCCode(cfg OIDCConfig, flow OIDCFlow, code string) (OIDCIdentity, error) {
+	discovery, err := discoverOIDC(cfg.Issuer)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("client_id", cfg.ClientID)
+	form.Set("code_verifier", flow.CodeVerifier)
+	if cfg.ClientSecret != "" {
+		form.Set("client_secret", cfg.ClientSecret)
+	}
+
+	resp, err := oidcHTTPClient.PostForm(discovery.TokenEndpoint, form)
+	if err != nil {
+		return OIDCIdentity{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return OIDCIdentity{}, fmt.Errorf("invalid token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || tokens.IDToken == "" {
+		return OIDCIdentity{}, fmt.Errorf("token request rejected: %s", tokens.Error)
+	}
+
+	return verifyIDToken(cfg, discovery, tokens.IDToken, flow.Nonce)
+}
+
+// verifyIDToken checks the ID token's signature, issuer, audience, expiry and nonce
+func verifyIDToken(cfg OIDCConfig, discovery oidcDiscovery, idToken, nonce string) (OIDCIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return oidcKey(discovery.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return OIDCIdentity{}, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return OIDCIdentity{}, errors.New("invalid ID token: nonce mismatch")
+	}
+
+	identity := OIDCIdentity{Issuer: discovery.Issuer}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+	identity.Username, _ = claims["preferred_username"].(string)
+	if identity.Subject == "" {
+		return OIDCIdentity{}, errors.New("invalid ID token: missing subject")
+	}
+	return identity, nil
+}
+
+// UserForOIDCIdentity finds the user linked to the identity. An unknown
+// identity is only accepted when the provider has verified its email: it is
+// linked to the user with that email, or a new user is created.
+func UserForOIDCIdentity(identity OIDCIdentity) (model.User, error) {
+	var user model.User
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var link model.UserIdentity
+		err := tx.Where("issuer = ? AND subject = ?", identity.Issuer, identity.Subject).First(&link).Error
+		if err == nil {
+			return tx.First(&user, link.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if identity.Email == "" {
+			return errors.New("the identity provider did not share an email address")
+		}
+		// ✅ An unverified address could belong to anyone, don't build an account on it
+		if !identity.EmailVerified {
+			return errors.New("the identity provider has not verified the email address")
+		}
+
+		err = tx.Where("email = ?", identity.Email).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			user, err = createOIDCUser(tx, identity)
+		}
+		if err != nil {
+			return err
+		}
+
+		if user.EmailVerifiedAt == nil {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+			if err := tx.Model(&user).Update("email_verified_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&model.UserIdentity{
+			UserID:  user.ID,
+			Issuer:  identity.Issuer,
+			Subject: identity.Subject,
+			Email:   identity.Email,
+		}).Error
+	})
+	return user, err
+}
+
+// createOIDCUser registers a first-time SSO user. They have no password
+// (the "!" hash never matches), so they can only log in through SSO until
+// they reset it.
+func createOIDCUser(tx *gorm.DB, identity OIDCIdentity) (model.User, error) {
+	base := identity.Username
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	username := base
+	for i := 2; ; i++ {
+		var count int64
+		tx.Model(&model.User{}).Where("username = ?", username).Count(&count)
+		if count == 0 {
+			break
+		}
+		username = fmt.Sprintf("%s%d", base, i)
+	}
+
+	user := model.User{
+		Username:     username,
+		Email:        identity.Email,
+		PasswordHash: "!",
+		Role:         model.RoleUser,
+	}
+	return user, tx.Create(&user).Error
+}
+
+// discoverOIDC fetches (and caches) the provider's discovery document
+func discoverOIDC(issuer string) (oidcDiscovery, error) {
+	oidcProvider.Lock()
+	defer oidcProvider.Unlock()
+
+	cached := strings.TrimSuffix(oidcProvider.discovery.Issuer, "/")
+	if cached == issuer && time.Since(oidcProvider.fetchedAt) < oidcDiscoveryTTL {
+		return oidcProvider.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := getJSON(issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return discovery, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return discovery, fmt.Errorf("OIDC discovery issuer %q does not match %q", discovery.Issuer, issuer)
+	}
+
+	oidcProvider.discovery = discovery
+	oidcProvider.fetchedAt = time.Now()
+	oidcProvider.keys = nil
+	return discovery, nil
+}
+
+// oidcKey returns the provider's signing key, refetching the JWKS once if the kid is unknown (key rotation)
+func oidcKey(jwksURI, kid string) (crypto.PublicKey, error) {
+	oidcProvider.Lock()
+	defer oidcProvider.Unlock()
+
+	if key, ok := oidcProvider.keys[kid]; ok {
+		return key, nil
+	}
+
+	var jwks struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := getJSON(jwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("fetching JWKS failed: %w", err)
+	}
+
+	oidcProvider.keys = map[string]crypto.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.PublicKey(); err == nil {
+			oidcProvider.keys[jwk.Kid] = key
+		}
+	}
+
+	if key, ok := oidcProvider.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no signing key with kid %q", kid)
+}
+
+// JWK is a single JSON Web Key (RFC 7517) for an RSA, EC or Ed25519 public key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// PublicKey decodes the JWK into a Go public key
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	case "OKP":
+		x, err := decode(k.X)
+		if err != nil || k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("unsupported OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func getJSON(url string, target any) error {
+	resp, err := oidcHTTPClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	outboxPollInterval  = 5 * time.Second
+	outboxBatchSize     = 20
+	outboxMaxAttempts   = 8
+	outboxBaseBackoff   = 30 * time.Second
+	outboxMaxBackoff    = time.Hour
+	outboxLease         = 5 * time.Minute // Time a worker has to send a claimed batch before another may retry it
+	dueSoonWindow       = 24 * time.Hour
+	dueSoonPollInterval = 15 * time.Minute
+)
+
+// FrontendURL is used to build links in emails
+var FrontendURL = func() string {
+	if url := os.Getenv("FRONTEND_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:3000"
+}()
+
+// TaskURL returns the frontend link for a task. Task IDs are only unique per
+// owner, so the owner is part of the link: without it a watcher would land on
+// their own task with the same number.
+func TaskURL(ownerID, taskID uint) string {
+	return fmt.Sprintf("%s/dashboard?owner=%d&task=%d", FrontendURL, ownerID, taskID)
+}
+
+// QueueEmail renders a template and stores it in the outbox. It never talks
+// to the mail server, so handlers can call it without blocking on SMTP.
+func QueueEmail(to, templateName string, data any) error {
+	subject, body, err := RenderEmail(templateName, data)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Create(&model.OutboxEmail{
+		To:            to,
+		Subject:       subject,
+		Body:          body,
+		Status:        "pending",
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// StartMailWorker delivers queued emails in the background until the process exits
+func StartMailWorker() {
+	if MailClient == nil {
+		InitMailer()
+	}
+
+	go func() {
+		for {
+			if err := deliverOutboxBatch(); err != nil {
+				log.Println("❌ Mail worker error:", err)
+			}
+			time.Sleep(outboxPollInterval)
+		}
+	}()
+}
+
+// deliverOutboxBatch sends due emails. The batch is claimed with a lease in a
+// short transaction (SKIP LOCKED, so several backend instances never claim the
+// same rows) and sent after it commits, so a slow mail server holds no locks.
+func deliverOutboxBatch() error {
+	var emails []model.OutboxEmail
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+			Order("id").Limit(outboxBatchSize).
+			Find(&emails).Error
+		if err != nil || len(emails) == 0 {
+			return err
+		}
+
+		// ✅ Count the attempt when claiming, so an email that crashes the worker still runs out of attempts
+		ids := make([]uint, len(emails))
+		for i := range emails {
+			ids[i] = emails[i].ID
+			emails[i].Attempts++
+		}
+		return tx.Model(&model.OutboxEmail{}).Where("id IN ?", ids).Updates(map[string]any{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": time.Now().Add(outboxLease),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, email := range emails {
+		updates := map[string]any{}
+		if err := MailClient.Send(Email{To: email.To, Subject: email.Subject, Body: email.Body}); err != nil {
+			updates["last_error"] = err.Error()
+			if email.Attempts >= outboxMaxAttempts {
+				updates["status"] = "failed"
+			} else {
+				updates["next_attempt_at"] = time.Now().Add(outboxBackoff(email.Attempts))
+			}
+		} else {
+			updates["status"] = "sent"
+			updates["sent_at"] = time.Now()
+			updates["last_error"] = ""
+		}
+
+		if err := database.DB.Model(&model.OutboxEmail{}).Where("id = ?", email.ID).Updates(updates).Error; err != nil {
+			log.Println("❌ Failed to record email delivery:", err)
+		}
+	}
+	return nil
+}
+
+// outboxBackoff doubles the wait after every failed attempt, capped at outboxMaxBackoff
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff << (attempts - 1)
+	if backoff <= 0 || backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return backoff
+}
+
+// StartDueSoonReminders periodically emails owners of unfinished tasks due within the next day
+func StartDueSoonReminders() {
+	go func() {
+		for {
+			if err := queueDueSoonReminders(); err != nil {
+				log.Println("❌ Due-soon reminder error:", err)
+			}
+			time.Sleep(dueSoonPollInterval)
+		}
+	}()
+}
+
+func queueDueSoonReminders() error {
+	now := time.Now()
+
+	var tasks []model.Task
+	err := database.DB.
+		Where("due_date BETWEEN ? AND ?", now, now.Add(dueSoonWindow)).
+		Where("status <> ? AND reminder_sent_at IS NULL", model.StatusCompleted).
+		Find(&tasks).Error
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		var owner model.User
+		if err := database.DB.First(&owner, task.UserID).Error; err != nil {
+			continue
+		}
+
+		err := QueueEmail(owner.Email, TemplateDueSoon, map[string]any{
+			"Username":  owner.Username,
+			"TaskTitle": task.Title,
+			"DueDate":   task.DueDate.Format("Mon Jan 2 15:04 MST"),
+			"Status":    task.Status,
+			"TaskURL":   TaskURL(task.UserID, task.TaskID),
+		})
+		if err != nil {
+			return err
+		}
+
+		database.DB.Model(&model.Task{}).
+			Where("user_id = ? AND task_id = ?", task.UserID, task.TaskID).
+			UpdateColumn("reminder_sent_at", now)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+// probeMailer records what it sends and checks the outbox row isn't locked while sending
+type probeMailer struct {
+	t    *testing.T
+	fail bool
+	sent []Email
+}
+
+func (m *probeMailer) Send(msg Email) error {
+	err := database.DB.Model(&model.OutboxEmail{}).Where(`"to" = ?`, msg.To).Update("last_error", "sending").Error
+	if err != nil {
+		m.t.Errorf("outbox row is locked while sending: %v", err)
+	}
+
+	var email model.OutboxEmail
+	database.DB.Where(`"to" = ?`, msg.To).First(&email)
+	if email.Attempts != 1 || !email.NextAttemptAt.After(time.Now().Add(time.Minute)) {
+		m.t.Errorf("claimed email has attempts = %d, next attempt at %v; want 1 and a lease", email.Attempts, email.NextAttemptAt)
+	}
+
+	m.sent = append(m.sent, msg)
+	if m.fail {
+		return errors.New("mail server unavailable")
+	}
+	return nil
+}
+
+func TestDeliverOutboxBatch(t *testing.T) {
+	tests := []struct {
+		name string
+		fail bool
+	}{
+		{"sent", false},
+		{"failed", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t, &model.OutboxEmail{})
+			mailer := &probeMailer{t: t, fail: tt.fail}
+			previous := MailClient
+			MailClient = mailer
+			t.Cleanup(func() { MailClient = previous })
+
+			err := QueueEmail("someone@example.com", TemplateVerifyEmail, map[string]any{
+				"Username":  "someone",
+				"VerifyURL": "http://localhost:3000/auth/verify-email?token=x",
+				"ExpiresIn": "48 hours",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := deliverOutboxBatch(); err != nil {
+				t.Fatal(err)
+			}
+			if err := deliverOutboxBatch(); err != nil { // Nothing is due any more
+				t.Fatal(err)
+			}
+
+			var email model.OutboxEmail
+			database.DB.First(&email)
+			if len(mailer.sent) != 1 {
+				t.Fatalf("sent %d times, want once", len(mailer.sent))
+			}
+			if tt.fail && (email.Status != "pending" || email.Attempts != 1 || email.LastError == "" || !email.NextAttemptAt.After(time.Now())) {
+				t.Fatalf("after a failed send: %+v, want pending with a retry scheduled", email)
+			}
+			if !tt.fail && (email.Status != "sent" || email.SentAt == nil || email.LastError != "") {
+				t.Fatalf("after a successful send: %+v, want sent", email)
+			}
+		})
+	}
+}
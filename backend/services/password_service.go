@@ -0,0 +1,202 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrWeakPassword wraps every password policy failure; the message says what to fix
+var ErrWeakPassword = errors.New("password does not meet the policy")
+
+// bcrypt ignores everything past 72 bytes, so longer passwords are rejected
+// rather than silently truncated
+const bcryptMaxPasswordBytes = 72
+
+// PasswordPolicy is what a new password must satisfy
+type PasswordPolicy struct {
+	MinLength      int     // In characters
+	MinEntropyBits float64 // See EstimatePasswordEntropy
+	BreachedDir    string  // Directory of SHA-1 prefix files, empty to skip the check
+}
+
+// CurrentPasswordPolicy reads the policy from the environment
+// (PASSWORD_MIN_LENGTH, PASSWORD_MIN_ENTROPY, BREACHED_PASSWORDS_DIR)
+func CurrentPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:      envInt("PASSWORD_MIN_LENGTH", 8),
+		MinEntropyBits: float64(envInt("PASSWORD_MIN_ENTROPY", 40)),
+		BreachedDir:    os.Getenv("BREACHED_PASSWORDS_DIR"),
+	}
+}
+
+// BcryptCost is the work factor for new hashes (BCRYPT_COST); existing hashes
+// below it are upgraded on the next successful login
+func BcryptCost() int {
+	cost := envInt("BCRYPT_COST", bcrypt.DefaultCost)
+	return min(max(cost, bcrypt.MinCost), bcrypt.MaxCost)
+}
+
+// ValidatePassword checks a new password against the policy. The user's own
+// username and email are passed in so passwords built from them are refused.
+func ValidatePassword(password string, personal ...string) error {
+	policy := CurrentPasswordPolicy()
+
+	if n := len([]rune(password)); n < policy.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrWeakPassword, policy.MinLength)
+	}
+	if len(password) > bcryptMaxPasswordBytes {
+		return fmt.Errorf("%w: must be at most %d bytes", ErrWeakPassword, bcryptMaxPasswordBytes)
+	}
+
+	lower := strings.ToLower(password)
+	for _, p := range personal {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if at := strings.IndexByte(p, '@'); at > 0 {
+			p = p[:at] // Compare against the local part of email addresses
+		}
+		if len(p) >= 3 && strings.Contains(lower, p) {
+			return fmt.Errorf("%w: must not contain your username or email", ErrWeakPassword)
+		}
+	}
+
+	if EstimatePasswordEntropy(password) < policy.MinEntropyBits {
+		return fmt.Errorf("%w: too easy to guess, try a longer passphrase or mix in other character types", ErrWeakPassword)
+	}
+
+	breached, err := IsBreachedPassword(policy.BreachedDir, password)
+	if err != nil {
+		// ✅ A broken list shouldn't block every signup, but it should be noticed
+		log.Println("❌ Failed to check breached password list:", err)
+	}
+	if breached {
+		return fmt.Errorf("%w: this password has appeared in a data breach, choose another", ErrWeakPassword)
+	}
+	return nil
+}
+
+// EstimatePasswordEntropy is a rough bits-of-entropy estimate: the size of
+// the character pool the password draws from, raised to its length, where
+// repeated characters and runs like "abc" or "321" only count once.
+func EstimatePasswordEntropy(password string) float64 {
+	var hasLower, hasUpper, hasDigit, hasSymbol, hasOther bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			hasSymbol = true
+		default:
+			hasOther = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{hasLower, 26}, {hasUpper, 26}, {hasDigit, 10}, {hasSymbol, 33}, {hasOther, 100}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	// Count characters that aren't a repeat or a continuation of a sequence
+	length := 0
+	runes := []rune(password)
+	for i, r := range runes {
+		if i > 0 {
+			step := r - runes[i-1]
+			if step == 0 || ((step == 1 || step == -1) && i > 1 && runes[i-1]-runes[i-2] == step) {
+				continue
+			}
+		}
+		length++
+	}
+
+	return float64(length) * math.Log2(float64(pool))
+}
+
+// IsBreachedPassword looks the password up in a local breached-password list
+// laid out like the Pwned Passwords range API: one file per 5-character
+// upper-case SHA-1 prefix (e.g. "5BAA6" or "5BAA6.txt"), each line holding the
+// remaining 35 characters of a hash, optionally followed by ":count".
+// Only the prefix file is read, never the whole list.
+func IsBreachedPassword(dir, password string) (bool, error) {
+	if dir == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	var file *os.File
+	var err error
+	for _, name := range []string{prefix, prefix + ".txt"} {
+		if file, err = os.Open(filepath.Join(dir, name)); err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+	if file == nil {
+		return false, nil // No file for this prefix means no known breach
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// HashPassword bcrypt-hashes a password at the configured cost
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), BcryptCost())
+	return string(hash), err
+}
+
+// RehashPasswordIfNeeded upgrades a user's hash after a successful login if
+// it was made with a lower cost than is now configured
+func RehashPasswordIfNeeded(user model.User, password string) {
+	cost, err := bcrypt.Cost([]byte(user.PasswordHash))
+	if err != nil || cost >= BcryptCost() {
+		return
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		log.Println("❌ Failed to rehash password:", err)
+		return
+	}
+
+	// ✅ Only replace the hash we checked, in case the password changed meanwhile
+	database.DB.Model(&model.User{}).
+		Where("id = ? AND password_hash = ?", user.ID, user.PasswordHash).
+		Update("password_hash", hash)
+}
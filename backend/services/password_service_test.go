@@ -0,0 +1,118 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// breachedList writes a prefix file for each password into a temp directory,
+// the way the Pwned Passwords range files are laid out
+func breachedList(t *testing.T, suffix string, passwords ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, password := range passwords {
+		sum := sha1.Sum([]byte(password))
+		digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+		lines := "0000000000000000000000000000000000A:3\n" + digest[5:] + ":42\n"
+		if err := os.WriteFile(filepath.Join(dir, digest[:5]+suffix), []byte(lines), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestEstimatePasswordEntropy(t *testing.T) {
+	tests := []struct {
+		password string
+		want     float64
+	}{
+		{"", 0},
+		{"a", math.Log2(26)},
+		{"aaaaaaaa", math.Log2(26)},          // Repeats count once
+		{"abcdefgh", 2 * math.Log2(26)},      // So do runs
+		{"87654321", 2 * math.Log2(10)},      // Descending too
+		{"acegikmo", 8 * math.Log2(26)},      // Steps of two aren't a run
+		{"Ab1!", 4 * math.Log2(26+26+10+33)}, // Every class widens the pool
+		{"héllo", 4 * math.Log2(26+100)},
+	}
+
+	for _, tt := range tests {
+		if got := EstimatePasswordEntropy(tt.password); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("EstimatePasswordEntropy(%q) = %.2f, want %.2f", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestIsBreachedPassword(t *testing.T) {
+	const breached = "Tr0ub4dor&3"
+
+	notAFile := t.TempDir()
+	sum := sha1.Sum([]byte(breached))
+	os.Mkdir(filepath.Join(notAFile, strings.ToUpper(hex.EncodeToString(sum[:]))[:5]), 0o700)
+
+	tests := []struct {
+		name     string
+		dir      string
+		password string
+		want     bool
+		wantErr  bool
+	}{
+		{"no list configured", "", breached, false, false},
+		{"listed in the prefix file", breachedList(t, "", breached), breached, true, false},
+		{"listed in a .txt prefix file", breachedList(t, ".txt", breached), breached, true, false},
+		{"same prefix file, other suffix", breachedList(t, "", breached), breached + "x", false, false},
+		{"no prefix file", breachedList(t, "", "something else"), breached, false, false},
+		{"unreadable prefix file", notAFile, breached, false, true},
+	}
+
+	for _, tt := range tests {
+		got, err := IsBreachedPassword(tt.dir, tt.password)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("%s: IsBreachedPassword = %v, %v; want %v, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("PASSWORD_MIN_ENTROPY", "50")
+	t.Setenv("BREACHED_PASSWORDS_DIR", breachedList(t, "", "correct-Horse-battery-staple"))
+
+	personal := []string{"alice", "alice.smith@example.com"}
+	tests := []struct {
+		name     string
+		password string
+		want     string // Part of the error message, empty when the password is accepted
+	}{
+		{"strong passphrase", "violet-Tractor-humming-9", ""},
+		{"too short", "v1olet-Tr", "at least 12 characters"},
+		{"past bcrypt's limit", strings.Repeat("violet-Tractor-humming-9", 4), "at most 72 bytes"},
+		{"contains the username", "violet-ALICE-humming-9", "username or email"},
+		{"contains the email's local part", "alice.smith-humming-9", "username or email"},
+		{"long but predictable", "aaaaaaaaaaaaaaaaaaaa", "too easy to guess"},
+		{"a keyboard run", "abcdefghijklmnop", "too easy to guess"},
+		{"breached", "correct-Horse-battery-staple", "data breach"},
+	}
+
+	for _, tt := range tests {
+		err := ValidatePassword(tt.password, personal...)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.want != "" && (!errors.Is(err, ErrWeakPassword) || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("%s: error = %v, want ErrWeakPassword saying %q", tt.name, err, tt.want)
+		}
+	}
+
+	// ✅ Short personal strings don't block unrelated passwords
+	if err := ValidatePassword("violet-Tractor-humming-9", "vi", ""); err != nil {
+		t.Errorf("two-letter username blocked a password: %v", err)
+	}
+}
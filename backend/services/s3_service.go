@@ -1,15 +1,22 @@
 package services
 
 import (
+	"fmt"
+	"io"
+	"log"
 	"mime/multipart"
+	"net/url"
 	"os"
-
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
 )
 
 var S3Client *s3.S3
@@ -46,3 +53,143 @@ func GeneratePreSignedURL(key string) (string, error) {
 	})
 	return req.Presign(15 * time.Minute) // Expires in 15 minutes
 }
+
+// DownloadFromS3 opens an object for reading; the caller closes it
+func DownloadFromS3(key string) (io.ReadCloser, error) {
+	out, err := S3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// ListS3Keys returns the key of every object under a prefix
+func ListS3Keys(prefix string) ([]string, error) {
+	var keys []string
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(BucketName),
+		Prefix: aws.String(prefix),
+	}
+	err := S3Client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, item := range page.Contents {
+			keys = append(keys, *item.Key)
+		}
+		return true
+	})
+	return keys, err
+}
+
+func DeleteFromS3(key string) error {
+	_, err := S3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(BucketName),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// DeleteS3Prefix removes every object under a prefix (e.g. a deleted user's files)
+func DeleteS3Prefix(prefix string) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(BucketName),
+		Prefix: aws.String(prefix),
+	}
+	return S3Client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, item := range page.Contents {
+			if err := DeleteFromS3(*item.Key); err != nil {
+				log.Println("❌ Failed to delete S3 object:", *item.Key, err)
+			}
+		}
+		return true
+	})
+}
+
+// UserFilesPrefix is where everything a user uploads is stored
+func UserFilesPrefix(userID uint) string {
+	return fmt.Sprintf("users/%d/", userID)
+}
+
+// UserExportPrefix is where a user's data export ZIPs are stored
+func UserExportPrefix(userID uint) string {
+	return UserFilesPrefix(userID) + "exports/"
+}
+
+// TaskAttachmentPrefix is where a task's attachments are stored. Task IDs are
+// only unique per user, so the owner is part of the path.
+func TaskAttachmentPrefix(userID, taskID uint) string {
+	return fmt.Sprintf("%stasks/%d/", UserFilesPrefix(userID), taskID)
+}
+
+// legacyAttachmentRoot is where attachments were kept before the owner
+// became part of the path, as tasks/<task id>/<file>
+const legacyAttachmentRoot = "tasks/"
+
+// MigrateLegacyAttachments moves attachments from the old layout to
+// TaskAttachmentPrefix, so they show up in listings and exports again.
+//
+// Task IDs are only unique per user, so a file moves only when exactly one
+// user had a task with its ID when it was uploaded. Anything ambiguous is
+// logged and left where it is rather than shown to the wrong user. Moved
+// files are gone from the old layout, so running it on every start is safe.
+func MigrateLegacyAttachments() {
+	if BucketName == "" {
+		return
+	}
+
+	moved, skipped := 0, 0
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(BucketName),
+		Prefix: aws.String(legacyAttachmentRoot),
+	}
+	err := S3Client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, item := range page.Contents {
+			key := *item.Key
+			idPart, name, _ := strings.Cut(strings.TrimPrefix(key, legacyAttachmentRoot), "/")
+			taskID, err := strconv.ParseUint(idPart, 10, 64)
+			if err != nil || name == "" {
+				continue
+			}
+
+			// ✅ A file can't belong to a task created after it was uploaded
+			var owners []uint
+			database.DB.Model(&model.Task{}).
+				Where("task_id = ? AND created_at <= ?", taskID, *item.LastModified).
+				Pluck("user_id", &owners)
+			if len(owners) != 1 {
+				log.Printf("❌ Not migrating attachment %s: %d users have a matching task", key, len(owners))
+				skipped++
+				continue
+			}
+
+			if err := moveS3Object(key, TaskAttachmentPrefix(owners[0], uint(taskID))+name); err != nil {
+				log.Println("❌ Failed to migrate attachment:", key, err)
+				skipped++
+				continue
+			}
+			moved++
+		}
+		return true
+	})
+	if err != nil {
+		log.Println("❌ Failed to list legacy attachments:", err)
+	}
+	if moved > 0 || skipped > 0 {
+		log.Printf("✅ Migrated %d legacy attachments (%d left in %s)", moved, skipped, legacyAttachmentRoot)
+	}
+}
+
+// moveS3Object copies an object to a new key, then deletes the original
+func moveS3Object(from, to string) error {
+	_, err := S3Client.CopyObject(&s3.CopyObjectInput{
+		Bucket:     aws.String(BucketName),
+		CopySource: aws.String(url.PathEscape(BucketName + "/" + from)),
+		Key:        aws.String(to),
+		ACL:        aws.String("private"),
+	})
+	if err != nil {
+		return err
+	}
+	return DeleteFromS3(from)
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is the private key our JWTs are currently signed with
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+var (
+	activeSigningKey *signingKey
+	// verificationKeys holds every public key we still accept, by kid: the
+	// active one plus retired ones whose tokens may not have expired yet
+	verificationKeys = map[string]crypto.PublicKey{}
+)
+
+// InitSigningKeys loads the JWT keys and stops the server if there's no
+// signing key, rather than falling back to something guessable.
+//
+//	JWT_SIGNING_KEY_FILE        PEM RSA (RS256) or Ed25519 (EdDSA) private key
+//	JWT_VERIFICATION_KEY_FILES  comma-separated PEM keys of previous signing
+//	                            keys, still accepted while rotating
+//
+// To rotate: move the old key into JWT_VERIFICATION_KEY_FILES, point
+// JWT_SIGNING_KEY_FILE at the new one, and drop the old one once
+// AccessTokenTTL has passed.
+func InitSigningKeys() {
+	path := os.Getenv("JWT_SIGNING_KEY_FILE")
+	if path == "" {
+		log.Fatal("❌ JWT_SIGNING_KEY_FILE is not set, refusing to start without a token signing key")
+	}
+
+	key, err := loadSigningKey(path)
+	if err != nil {
+		log.Fatal("❌ Failed to load JWT signing key:", err)
+	}
+	activeSigningKey = key
+	verificationKeys[key.kid] = key.key.Public()
+
+	for _, path := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		pub, err := loadPublicKey(path)
+		if err != nil {
+			log.Fatal("❌ Failed to load JWT verification key ", path, ": ", err)
+		}
+		kid, err := keyID(pub)
+		if err != nil {
+			log.Fatal("❌ Failed to load JWT verification key ", path, ": ", err)
+		}
+		verificationKeys[kid] = pub
+	}
+
+	log.Printf("✅ Signing tokens with %s key %s (%d verification keys)", key.method.Alg(), key.kid, len(verificationKeys))
+}
+
+// Token types, sent as the typ claim. A token only verifies as the type it
+// was signed as, so an MFA challenge or SSO cookie never passes as an access token.
+const (
+	tokenTypeAccess   = "access"
+	tokenTypeMFA      = "mfa_challenge"
+	tokenTypeOIDCFlow = "oidc_flow"
+)
+
+// TokenIssuer is the iss claim of every token we sign (JWT_ISSUER)
+var TokenIssuer = func() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "go-task-management"
+}()
+
+// AccessTokenAudience is the aud claim of access tokens (JWT_AUDIENCE), for
+// other services verifying them through the JWKS endpoint. Tokens only this
+// server reads carry the issuer as their audience.
+var AccessTokenAudience = func() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
+	}
+	return "go-task-management-api"
+}()
+
+func tokenAudience(typ string) string {
+	if typ == tokenTypeAccess {
+		return AccessTokenAudience
+	}
+	return TokenIssuer
+}
+
+// signJWT signs claims as a token of the given type with the active key,
+// naming the key in the kid header
+func signJWT(typ string, claims jwt.MapClaims) (string, error) {
+	if activeSigningKey == nil {
+		return "", errors.New("no JWT signing key loaded")
+	}
+
+	claims["iss"] = TokenIssuer
+	claims["aud"] = tokenAudience(typ)
+	claims["typ"] = typ
+
+	token := jwt.NewWithClaims(activeSigningKey.method, claims)
+	token.Header["kid"] = activeSigningKey.kid
+	return token.SignedString(activeSigningKey.key)
+}
+
+// parseJWT verifies a token of the given type signed by any of our verification keys
+func parseJWT(tokenString, typ string) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := verificationKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(TokenIssuer),
+		jwt.WithAudience(tokenAudience(typ)),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return token, err
+	}
+
+	if claims, ok := token.Claims.(jwt.MapClaims); !ok || claims["typ"] != typ {
+		return token, fmt.Errorf("not a %s token", typ)
+	}
+	return token, nil
+}
+
+// SigningKeySet returns the public keys other services can verify our tokens with
+func SigningKeySet() []JWK {
+	keys := make([]JWK, 0, len(verificationKeys))
+	for kid, pub := range verificationKeys {
+		switch pub := pub.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "RSA", Kid: kid, Use: "sig", Alg: jwt.SigningMethodRS256.Alg(),
+				N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "OKP", Kid: kid, Use: "sig", Alg: jwt.SigningMethodEdDSA.Alg(),
+				Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	// ✅ Stable order so caches and diffs don't churn
+	slices.SortFunc(keys, func(a, b JWK) int { return strings.Compare(a.Kid, b.Kid) })
+	return keys
+}
+
+func loadSigningKey(path string) (*signingKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed any
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		key.key, key.method = k, jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		key.key, key.method = k, jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+
+	key.kid, err = keyID(key.key.Public())
+	return key, err
+}
+
+// loadPublicKey reads a public key, or the public half of a private key file
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		key, err := loadSigningKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return key.key.Public(), nil
+	}
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return block, nil
+}
+
+// keyID derives a stable kid from the public key, so every instance loading
+// the same key file agrees on it
+func keyID(pub crypto.PublicKey) (string, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return "", fmt.Errorf("unsupported key type %T, use RSA or Ed25519", pub)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
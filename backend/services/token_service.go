@@ -0,0 +1,360 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	AccessTokenTTL          = 15 * time.Minute
+	MFAChallengeTTL         = 5 * time.Minute
+	MaxMFAChallengeAttempts = 5 // Codes one MFA challenge accepts before the user must log in again
+	RefreshTokenTTL         = 30 * 24 * time.Hour
+	sessionTouchInterval    = time.Minute
+)
+
+var (
+	ErrAccessTokenExpired  = errors.New("token expired")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+	ErrMFAChallengeInvalid = errors.New("MFA challenge expired, used or out of attempts")
+)
+
+// TokenPair is what a successful login or refresh hands back to the client
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"` // Access token expiry
+}
+
+// IssueAccessToken signs a short-lived JWT bound to a refresh token family
+func IssueAccessToken(user model.User, familyID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(AccessTokenTTL)
+	signed, err := signJWT(tokenTypeAccess, jwt.MapClaims{
+		"user_id": user.ID,
+		"role":    user.Role,
+		"sid":     familyID,
+		"iat":     time.Now().Unix(),
+		"exp":     expiresAt.Unix(),
+	})
+	return signed, expiresAt, err
+}
+
+// IssueImpersonationToken starts a support session in which admin acts as
+// target. It has no refresh token, so it ends when the access token expires.
+func IssueImpersonationToken(admin, target model.User, userAgent, ip string) (string, time.Time, error) {
+	familyID, err := randomHex(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	session := model.Session{
+		ID:             familyID,
+		UserID:         target.ID,
+		UserAgent:      userAgent,
+		IP:             ip,
+		LastSeenAt:     now,
+		ImpersonatorID: &admin.ID,
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := now.Add(AccessTokenTTL)
+	signed, err := signJWT(tokenTypeAccess, jwt.MapClaims{
+		"user_id": target.ID,
+		"role":    target.Role,
+		"sid":     familyID,
+		"imp":     admin.ID,
+		"iat":     now.Unix(),
+		"exp":     expiresAt.Unix(),
+	})
+	return signed, expiresAt, err
+}
+
+// ParseAccessToken verifies the signature and expiry of an access token
+func ParseAccessToken(tokenString string) (jwt.MapClaims, error) {
+	return parseToken(tokenString, tokenTypeAccess)
+}
+
+// parseToken verifies a token of the given type and returns its claims
+func parseToken(tokenString, typ string) (jwt.MapClaims, error) {
+	token, err := parseJWT(tokenString, typ)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrAccessTokenExpired
+	}
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// IssueMFAChallenge records a pending second login step and signs a
+// short-lived token for it. Its typ keeps RequireAuth from accepting it.
+func IssueMFAChallenge(user model.User) (string, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(MFAChallengeTTL)
+	challenge := model.MFAChallenge{ID: id, UserID: user.ID, ExpiresAt: expiresAt}
+	if err := database.DB.Create(&challenge).Error; err != nil {
+		return "", err
+	}
+
+	return signJWT(tokenTypeMFA, jwt.MapClaims{
+		"user_id": user.ID,
+		"jti":     id,
+		"iat":     time.Now().Unix(),
+		"exp":     expiresAt.Unix(),
+	})
+}
+
+// ParseMFAChallenge checks an MFA challenge token and counts a code attempt
+// against it. After MaxMFAChallengeAttempts codes, or once one succeeded,
+// the token stops working and the user has to log in again.
+func ParseMFAChallenge(tokenString string) (model.MFAChallenge, error) {
+	var challenge model.MFAChallenge
+
+	claims, err := parseToken(tokenString, tokenTypeMFA)
+	if err != nil {
+		return challenge, err
+	}
+	id, _ := claims["jti"].(string)
+	if id == "" {
+		return challenge, errors.New("not an MFA challenge token")
+	}
+
+	// ✅ One statement, so concurrent requests can't try more codes than allowed
+	result := database.DB.Model(&model.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL AND attempts < ? AND expires_at > ?", id, MaxMFAChallengeAttempts, time.Now()).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return challenge, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return challenge, ErrMFAChallengeInvalid
+	}
+
+	err = database.DB.First(&challenge, "id = ?", id).Error
+	return challenge, err
+}
+
+// CompleteMFAChallenge marks the challenge used after a correct code, so
+// its token can't start a second session
+func CompleteMFAChallenge(id string) error {
+	result := database.DB.Model(&model.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFAChallengeInvalid
+	}
+	return nil
+}
+
+// IssueTokenPair starts a new session (refresh token family) for a fresh login
+func IssueTokenPair(user model.User, userAgent, ip string) (TokenPair, error) {
+	familyID, err := randomHex(16)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	var pair TokenPair
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		session := model.Session{
+			ID:         familyID,
+			UserID:     user.ID,
+			UserAgent:  userAgent,
+			IP:         ip,
+			LastSeenAt: now,
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		pair, err = issueTokenPair(tx, user, familyID)
+		return err
+	})
+	return pair, err
+}
+
+// RotateRefreshToken exchanges a refresh token for a new pair. Each refresh
+// token works once: presenting one that was already rotated revokes its
+// whole family, logging out both the thief and the legitimate client.
+func RotateRefreshToken(raw string) (TokenPair, model.User, error) {
+	var pair TokenPair
+	var user model.User
+	reused := false
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var current model.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hashToken(raw)).First(&current).Error
+		if err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		if current.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+		if current.UsedAt != nil {
+			reused = true
+			return revokeFamily(tx, current.FamilyID)
+		}
+
+		if err := tx.First(&user, current.UserID).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		now := time.Now()
+		if err := tx.Model(&current).Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		pair, err = issueTokenPair(tx, user, current.FamilyID)
+		return err
+	})
+
+	if err == nil && reused {
+		err = ErrRefreshTokenReused
+	}
+	return pair, user, err
+}
+
+// RevokeRefreshToken ends the session a refresh token belongs to
+func RevokeRefreshToken(raw string) error {
+	var current model.RefreshToken
+	if err := database.DB.Where("token_hash = ?", hashToken(raw)).First(&current).Error; err != nil {
+		return ErrInvalidRefreshToken
+	}
+	return revokeFamily(database.DB, current.FamilyID)
+}
+
+// RevokeSession ends the session identified by an access token's sid claim
+func RevokeSession(sessionID string) error {
+	return revokeFamily(database.DB, sessionID)
+}
+
+// RevokeAllSessions signs a user out everywhere
+func RevokeAllSessions(userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&model.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeOtherSessions signs the user out everywhere except the given session
+func RevokeOtherSessions(userID uint, keepSessionID string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&model.Session{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepSessionID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.RefreshToken{}).
+			Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepSessionID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// CheckSession reports whether a session is still live, recording the
+// activity at most once per sessionTouchInterval to avoid a write per request.
+func CheckSession(sessionID string) bool {
+	var session model.Session
+	if err := database.DB.Where("id = ? AND revoked_at IS NULL", sessionID).First(&session).Error; err != nil {
+		return false
+	}
+
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		database.DB.Model(&session).UpdateColumn("last_seen_at", time.Now())
+	}
+	return true
+}
+
+func issueTokenPair(tx *gorm.DB, user model.User, familyID string) (TokenPair, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refresh := model.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}
+	if err := tx.Create(&refresh).Error; err != nil {
+		return TokenPair{}, err
+	}
+
+	access, expiresAt, err := IssueAccessToken(user, familyID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{AccessToken: access, RefreshToken: raw, ExpiresAt: expiresAt}, nil
+}
+
+// revokeFamily revokes a session and every refresh token issued for it
+func revokeFamily(tx *gorm.DB, familyID string) error {
+	now := time.Now()
+	if err := tx.Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
+
+// hashToken is how opaque tokens are looked up without storing them
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns 256 random bits, URL-safe encoded
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
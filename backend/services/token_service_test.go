@@ -0,0 +1,95 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// useTestSigningKey signs tokens with a throwaway Ed25519 key for the test
+func useTestSigningKey(t *testing.T) {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, err := keyID(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := activeSigningKey
+	activeSigningKey = &signingKey{kid: kid, method: jwt.SigningMethodEdDSA, key: key}
+	verificationKeys[kid] = key.Public()
+	t.Cleanup(func() {
+		activeSigningKey = previous
+		delete(verificationKeys, kid)
+	})
+}
+
+func TestTokensOnlyVerifyAsTheirType(t *testing.T) {
+	useTestSigningKey(t)
+
+	types := []string{tokenTypeAccess, tokenTypeMFA, tokenTypeOIDCFlow}
+	for _, signedAs := range types {
+		token, err := signJWT(signedAs, jwt.MapClaims{"user_id": 1, "exp": time.Now().Add(time.Minute).Unix()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, parsedAs := range types {
+			_, err := parseToken(token, parsedAs)
+			if ok := err == nil; ok != (signedAs == parsedAs) {
+				t.Errorf("%s token parsed as %s: err = %v", signedAs, parsedAs, err)
+			}
+		}
+	}
+}
+
+func TestParseAccessTokenChecksIssuerAndAudience(t *testing.T) {
+	useTestSigningKey(t)
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":     TokenIssuer,
+			"aud":     AccessTokenAudience,
+			"typ":     tokenTypeAccess,
+			"user_id": 1,
+			"exp":     time.Now().Add(time.Minute).Unix(),
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(jwt.MapClaims)
+		ok     bool
+	}{
+		{"valid", func(jwt.MapClaims) {}, true},
+		{"other issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, false},
+		{"no issuer", func(c jwt.MapClaims) { delete(c, "iss") }, false},
+		{"other audience", func(c jwt.MapClaims) { c["aud"] = "another-service" }, false},
+		{"no audience", func(c jwt.MapClaims) { delete(c, "aud") }, false},
+		{"no type", func(c jwt.MapClaims) { delete(c, "typ") }, false},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(claims)
+
+			token := jwt.NewWithClaims(activeSigningKey.method, claims)
+			token.Header["kid"] = activeSigningKey.kid
+			signed, err := token.SignedString(activeSigningKey.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := ParseAccessToken(signed); (err == nil) != tt.ok {
+				t.Fatalf("ParseAccessToken err = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrAccountDisabled = errors.New("account disabled")
+
+// DisableUser blocks a user from logging in and signs out every session.
+// Their API tokens stop working too, since RequireAuth checks DisabledAt.
+func DisableUser(user model.User) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// ✅ Don't lock everyone out of the admin API
+		if err := ensureAnotherAdmin(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).Where("id = ?", user.ID).Update("disabled_at", time.Now()).Error
+	})
+	if err != nil {
+		return err
+	}
+	return RevokeAllSessions(user.ID)
+}
+
+// ChangeUserRole gives the user a new role, unless that would leave no active admin
+func ChangeUserRole(userID uint, role string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if role != model.RoleAdmin {
+			if err := ensureAnotherAdmin(tx, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.User{}).Where("id = ?", userID).Update("role", role).Error
+	})
+}
+
+// ensureAnotherAdmin returns ErrLastAdmin if the user is the only active admin.
+// The admin rows stay locked until the transaction ends, so two admins stepping
+// down at the same time can't each count the other and both succeed.
+func ensureAnotherAdmin(tx *gorm.DB, userID uint) error {
+	var admins []uint
+	err := tx.Model(&model.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND disabled_at IS NULL", model.RoleAdmin).
+		Order("id").Pluck("id", &admins).Error
+	if err != nil {
+		return err
+	}
+	if slices.Contains(admins, userID) && len(admins) <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// EnableUser lets a disabled user log in again
+func EnableUser(user model.User) error {
+	return database.DB.Model(&model.User{}).Where("id = ?", user.ID).Update("disabled_at", nil).Error
+}
+
+// AccountLockedUntil reports when a login lockout on the email ends (zero if not locked)
+func AccountLockedUntil(email string) time.Time {
+	state, err := LoginAttempts.Get(accountAttemptKey(email))
+	if err != nil || state.LockedUntil.Before(time.Now()) {
+		return time.Time{}
+	}
+	return state.LockedUntil
+}
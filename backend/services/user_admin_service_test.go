@@ -0,0 +1,40 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+)
+
+func TestLastActiveAdminIsKept(t *testing.T) {
+	setupTestDB(t, &model.User{})
+
+	now := time.Now()
+	users := []model.User{
+		{Username: "alice", Email: "alice@example.com", PasswordHash: "x", Role: model.RoleAdmin},
+		{Username: "bob", Email: "bob@example.com", PasswordHash: "x", Role: model.RoleAdmin},
+		{Username: "carol", Email: "carol@example.com", PasswordHash: "x", Role: model.RoleAdmin, DisabledAt: &now},
+	}
+	if err := database.DB.Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := users[0], users[1]
+
+	if err := ChangeUserRole(alice.ID, model.RoleUser); err != nil {
+		t.Fatalf("demoting one of two admins: %v", err)
+	}
+
+	// ✅ Bob is the last active admin, a disabled admin doesn't count
+	if err := ChangeUserRole(bob.ID, model.RoleUser); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("demoting the last admin: err = %v, want ErrLastAdmin", err)
+	}
+	if err := DisableUser(bob); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("disabling the last admin: err = %v, want ErrLastAdmin", err)
+	}
+	if err := ChangeUserRole(bob.ID, model.RoleAdmin); err != nil {
+		t.Fatalf("keeping the last admin an admin: %v", err)
+	}
+}
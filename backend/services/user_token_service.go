@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	VerifyEmailTokenTTL   = 48 * time.Hour
+	PasswordResetTokenTTL = time.Hour
+)
+
+var (
+	ErrInvalidUserToken = errors.New("invalid or expired token")
+	ErrEmailNotVerified = errors.New("email address not verified")
+)
+
+// RequireEmailVerification blocks login for unverified accounts when REQUIRE_EMAIL_VERIFICATION=true
+func RequireEmailVerification() bool {
+	return os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true"
+}
+
+// IssueUserToken creates a single-use token for the purpose, invalidating
+// any earlier unused token the user had for the same purpose.
+func IssueUserToken(userID uint, purpose, email string, ttl time.Duration) (string, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&model.UserToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			Email:     email,
+			TokenHash: hashToken(raw),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+	return raw, err
+}
+
+// LookupUserToken returns a token that is still usable without using it up,
+// for checks that must pass before the token is consumed
+func LookupUserToken(raw, purpose string) (model.UserToken, error) {
+	var token model.UserToken
+	err := database.DB.Where("token_hash = ? AND purpose = ? AND used_at IS NULL", hashToken(raw), purpose).First(&token).Error
+	if err != nil || time.Now().After(token.ExpiresAt) {
+		return token, ErrInvalidUserToken
+	}
+	return token, nil
+}
+
+// ConsumeUserToken marks a token used and returns it. A token works once,
+// only for its purpose and only before it expires.
+func ConsumeUserToken(raw, purpose string) (model.UserToken, error) {
+	var token model.UserToken
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND purpose = ? AND used_at IS NULL", hashToken(raw), purpose).
+			First(&token).Error
+		if err != nil || time.Now().After(token.ExpiresAt) {
+			return ErrInvalidUserToken
+		}
+
+		return tx.Model(&token).Update("used_at", time.Now()).Error
+	})
+	return token, err
+}
+
+// RevokeUserTokens uses up every outstanding token of the user, e.g. when
+// their email changes and links sent to the old address must stop working
+func RevokeUserTokens(userID uint) error {
+	return database.DB.Model(&model.UserToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
+
+// SendEmailChangedNotice tells the old address that the account moved to a new one
+func SendEmailChangedNotice(user model.User, oldEmail string) error {
+	return QueueEmail(oldEmail, TemplateEmailChanged, map[string]any{
+		"Username": user.Username,
+		"NewEmail": user.Email,
+	})
+}
+
+// SendVerificationEmail emails the user a link confirming they own their address
+func SendVerificationEmail(user model.User) error {
+	raw, err := IssueUserToken(user.ID, model.TokenPurposeVerifyEmail, user.Email, VerifyEmailTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return QueueEmail(user.Email, TemplateVerifyEmail, map[string]any{
+		"Username":  user.Username,
+		"VerifyURL": FrontendURL + "/auth/verify-email?token=" + url.QueryEscape(raw),
+		"ExpiresIn": "48 hours",
+	})
+}
+
+// SendPasswordResetEmail emails the user a link to choose a new password
+func SendPasswordResetEmail(user model.User) error {
+	raw, err := IssueUserToken(user.ID, model.TokenPurposePasswordReset, user.Email, PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return QueueEmail(user.Email, TemplatePasswordReset, map[string]any{
+		"Username":  user.Username,
+		"ResetURL":  FrontendURL + "/auth/reset-password?token=" + url.QueryEscape(raw),
+		"ExpiresIn": "1 hour",
+	})
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tarun05rawat/go-task-management/database"
+	"github.com/tarun05rawat/go-task-management/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Events sent to webhooks
+const (
+	WebhookTaskCreated        = "task.created"
+	WebhookTaskUpdated        = "task.updated"
+	WebhookTaskDeleted        = "task.deleted"
+	WebhookAttachmentUploaded = "attachment.uploaded"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []string{WebhookTaskCreated, WebhookTaskUpdated, WebhookTaskDeleted, WebhookAttachmentUploaded}
+
+const (
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 20
+	webhookMaxAttempts  = 10
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = 6 * time.Hour
+	webhookLease        = 5 * time.Minute // A claimed delivery is retried after this if the worker dies
+	webhookTimeout      = 10 * time.Second
+	webhookMaxBodyDrain = 4096 // Read (and discard) this much of a response so the connection can be reused
+)
+
+var ErrWebhookAddressBlocked = errors.New("webhook URL points at a private or local address")
+
+// webhookAllowPrivate lets webhooks reach private addresses, for trying them
+// out against a receiver on localhost. Never set it where the backend can
+// reach internal services or cloud metadata.
+var webhookAllowPrivate = os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true"
+
+// webhookHTTPClient only connects to public addresses, checked on the
+// resolved IP at dial time so DNS tricks can't point a hook inside the
+// network, and never follows redirects (a 3xx counts as a failed delivery).
+var webhookHTTPClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		Proxy: nil, // A proxy would dial on our behalf and skip the address check
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if !webhookAddressAllowed(net.ParseIP(host)) {
+					return ErrWebhookAddressBlocked
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: webhookTimeout,
+		MaxIdleConnsPerHost:   2,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// carrierGradeNAT is 100.64.0.0/10, shared address space that isn't public either
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// webhookAddressAllowed rejects loopback, private, link-local (including
+// 169.254.169.254 metadata), multicast and unspecified addresses
+func webhookAddressAllowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if webhookAllowPrivate {
+		return true
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || carrierGradeNAT.Contains(ip))
+}
+
+// ValidateWebhookURL checks a webhook URL when it's registered: absolute
+// http(s), and resolving only to public addresses. Deliveries check again
+// when dialing, since DNS can change after registration.
+func ValidateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("URL must be an absolute http(s) URL")
+	}
+
+	ips, err := net.LookupIP(parsed.Hostname())
+	if err != nil || len(ips) == 0 {
+		return errors.New("URL host could not be resolved")
+	}
+	for _, ip := range ips {
+		if !webhookAddressAllowed(ip) {
+			return ErrWebhookAddressBlocked
+		}
+	}
+	return nil
+}
+
+// NewWebhookSecret returns a random hex secret used to sign payloads
+func NewWebhookSecret() (string, error) {
+	return randomHex(32)
+}
+
+// SignWebhookPayload returns the value of the X-Webhook-Signature header
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// subscribes reports whether the webhook wants the event (an empty list means all events)
+func subscribes(hook model.Webhook, event string) bool {
+	if strings.TrimSpace(hook.Events) == "" {
+		return true
+	}
+	for _, e := range strings.Split(hook.Events, ",") {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// EnqueueWebhooks queues a delivery of the event to every active webhook of the user
+func EnqueueWebhooks(userID uint, event string, data any) error {
+	var hooks []model.Webhook
+	if err := database.DB.Where("user_id = ? AND active = ?", userID, true).Find(&hooks).Error; err != nil {
+		return err
+	}
+
+	var deliveries []model.WebhookDelivery
+	for _, hook := range hooks {
+		if !subscribes(hook, event) {
+			continue
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			Status:        "pending",
+			NextAttemptAt: time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	// ✅ Payload is built per delivery so each one carries its own ID
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range deliveries {
+			deliveries[i].Payload = "{}"
+			if err := tx.Create(&deliveries[i]).Error; err != nil {
+				return err
+			}
+
+			payload, err := json.Marshal(map[string]any{
+				"id":         deliveries[i].ID,
+				"event":      event,
+				"created_at": deliveries[i].CreatedAt,
+				"data":       data,
+			})
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&deliveries[i]).Update("payload", string(payload)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RedeliverWebhook queues a fresh copy of an earlier delivery, leaving the original in the log
+func RedeliverWebhook(original model.WebhookDelivery) (model.WebhookDelivery, error) {
+	delivery := model.WebhookDelivery{
+		WebhookID:     original.WebhookID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        "pending",
+		NextAttemptAt: time.Now(),
+	}
+	err := database.DB.Create(&delivery).Error
+	return delivery, err
+}
+
+// StartWebhookWorker delivers queued webhook events in the background until the process exits
+func StartWebhookWorker() {
+	go func() {
+		for {
+			if err := deliverWebhookBatch(); err != nil {
+				log.Println("❌ Webhook worker error:", err)
+			}
+			time.Sleep(webhookPollInterval)
+		}
+	}()
+}
+
+// deliverWebhookBatch claims due deliveries in a short transaction (so other
+// instances skip them) and then POSTs them without holding any row locks.
+func deliverWebhookBatch() error {
+	var deliveries []model.WebhookDelivery
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+			Order("id").Limit(webhookBatchSize).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, d := range deliveries {
+			ids[i] = d.ID
+		}
+		return tx.Model(&model.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(webhookLease)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		var hook model.Webhook
+		if err := database.DB.First(&hook, delivery.WebhookID).Error; err != nil {
+			delivery.Status = "failed"
+			delivery.LastError = "webhook no longer exists"
+			database.DB.Save(&delivery)
+			continue
+		}
+
+		attemptWebhookDelivery(hook, &delivery)
+		if err := database.DB.Save(&delivery).Error; err != nil {
+			log.Println("❌ Failed to record webhook delivery:", err)
+		}
+	}
+	return nil
+}
+
+// attemptWebhookDelivery POSTs the payload once and records the outcome on the delivery
+func attemptWebhookDelivery(hook model.Webhook, delivery *model.WebhookDelivery) {
+	delivery.Attempts++
+
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "go-task-management-webhooks")
+		req.Header.Set("X-Webhook-Event", delivery.Event)
+		req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+		req.Header.Set("X-Webhook-Signature", SignWebhookPayload(hook.Secret, payload))
+
+		var resp *http.Response
+		resp, err = webhookHTTPClient.Do(req)
+		if err == nil {
+			// ✅ Only the status is kept: storing the body would let a hook read
+			// back whatever its URL answers
+			io.Copy(io.Discard, io.LimitReader(resp.Body, webhookMaxBodyDrain))
+			resp.Body.Close()
+
+			delivery.ResponseStatus = resp.StatusCode
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+			}
+		}
+	}
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = "delivered"
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = "failed"
+		return
+	}
+	delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+}
+
+// webhookBackoff doubles the wait after every failed attempt, capped at webhookMaxBackoff
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff << (attempts - 1)
+	if backoff <= 0 || backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
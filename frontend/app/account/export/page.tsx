@@ -0,0 +1,114 @@
+"use client";
+import { Suspense, useEffect, useState } from "react";
+import Link from "next/link";
+import { useRouter, useSearchParams } from "next/navigation";
+import axios from "axios";
+import { toast } from "sonner";
+import { Button } from "@/components/ui/button";
+import { useAuth } from "@/context/AuthContext";
+import api from "@/utils/api";
+
+type DataExport = {
+  id: number;
+  status: "pending" | "ready" | "failed" | "expired";
+  size_bytes: number;
+  created_at: string;
+  expires_at: string | null;
+};
+
+const errorMessage = (err: unknown) =>
+  (axios.isAxiosError(err) && err.response?.data?.error) ||
+  "An error occurred. Please try again.";
+
+// ✅ Opened from the "export ready" email (?id= highlights that export)
+function ExportPage() {
+  const { user, loading } = useAuth();
+  const router = useRouter();
+  const searchParams = useSearchParams();
+  const highlighted = Number(searchParams.get("id"));
+  const [exports, setExports] = useState<DataExport[]>([]);
+
+  const fetchExports = async () => {
+    const res = await api.get("/me/exports");
+    setExports(res.data);
+  };
+
+  useEffect(() => {
+    if (loading) return;
+    if (!user) {
+      router.push("/auth/login");
+      return;
+    }
+    fetchExports();
+  }, [user, loading, router]);
+
+  const requestExport = async () => {
+    try {
+      await api.post("/me/export");
+      toast.success("Export requested, we'll notify you when it's ready");
+      fetchExports();
+    } catch (err: unknown) {
+      toast.error(errorMessage(err));
+    }
+  };
+
+  // The download link is short-lived, so it's fetched on click
+  const download = async (id: number) => {
+    try {
+      const res = await api.get(`/me/exports/${id}/download`);
+      window.location.assign(res.data.url);
+    } catch (err: unknown) {
+      toast.error(errorMessage(err));
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-slate-900 text-white p-8">
+      <header className="flex justify-between items-center mb-8">
+        <h1 className="text-3xl font-bold">Your Data Exports</h1>
+        <div className="flex items-center gap-4">
+          <Button onClick={requestExport}>Request New Export</Button>
+          <Link href="/dashboard" className="text-blue-400">
+            Back to Dashboard
+          </Link>
+        </div>
+      </header>
+
+      <div className="space-y-4">
+        {exports.length === 0 && (
+          <p className="text-slate-400">You haven&apos;t requested any exports.</p>
+        )}
+        {exports.map((exp) => (
+          <div
+            key={exp.id}
+            className={`flex items-center justify-between bg-slate-800 p-4 rounded-lg ${
+              exp.id === highlighted ? "ring-2 ring-blue-500" : ""
+            }`}
+          >
+            <div>
+              <span className="block">
+                Requested {new Date(exp.created_at).toLocaleString()}
+              </span>
+              <small className="text-xs text-slate-400">
+                {exp.status === "ready" && exp.expires_at
+                  ? `Ready, available until ${new Date(exp.expires_at).toLocaleString()}`
+                  : exp.status}
+              </small>
+            </div>
+            {exp.status === "ready" && (
+              <Button onClick={() => download(exp.id)}>Download</Button>
+            )}
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+}
+
+export default function AccountExportPage() {
+  return (
+    <Suspense>
+      <ExportPage />
+    </Suspense>
+  );
+}
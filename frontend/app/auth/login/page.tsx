@@ -1,11 +1,36 @@
 "use client";
-import { useState } from "react";
+import { useEffect, useRef, useState } from "react";
+import Link from "next/link";
+import { toast } from "sonner";
 import { useAuth } from "@/context/AuthContext";
+import api from "@/utils/api";
 
 export default function Login() {
-  const { login } = useAuth();
+  const { login, completeMFA } = useAuth();
   const [email, setEmail] = useState("");
   const [password, setPassword] = useState("");
+  const handledRedirect = useRef(false);
+
+  // ✅ SSO sends the browser back here when it fails or still needs the second factor
+  useEffect(() => {
+    if (handledRedirect.current) return;
+    handledRedirect.current = true;
+
+    const params = new URLSearchParams(window.location.search);
+    const mfaToken = new URLSearchParams(window.location.hash.slice(1)).get(
+      "mfa_token"
+    );
+    if (params.has("error") || mfaToken) {
+      window.history.replaceState(null, "", window.location.pathname);
+    }
+
+    if (params.get("error") === "sso_failed") {
+      toast.error("Single sign-on failed. Please try again.");
+    }
+    if (mfaToken) {
+      completeMFA(mfaToken);
+    }
+  }, [completeMFA]);
 
   const handleLogin = async (e: React.FormEvent<HTMLFormElement>) => {
     // ✅ Fix TypeScript error
@@ -40,6 +65,15 @@ export default function Login() {
           Login
         </button>
       </form>
+      <a
+        href={`${api.defaults.baseURL}/auth/oidc/login`}
+        className="mt-4 bg-gray-700 hover:bg-gray-600 text-white px-6 py-2 rounded-md"
+      >
+        Sign in with SSO
+      </a>
+      <Link href="/auth/reset-password" className="mt-4 text-blue-400">
+        Forgot your password?
+      </Link>
     </div>
   );
 }
@@ -0,0 +1,126 @@
+"use client";
+import { Suspense, useEffect, useState } from "react";
+import Link from "next/link";
+import { useRouter, useSearchParams } from "next/navigation";
+import axios from "axios";
+import { toast } from "sonner";
+import api from "@/utils/api";
+
+const errorMessage = (err: unknown) =>
+  (axios.isAxiosError(err) && err.response?.data?.error) ||
+  "An error occurred. Please try again.";
+
+// ✅ Without a token: ask for a reset link. With one (from the email): choose a new password.
+function ResetPassword() {
+  const searchParams = useSearchParams();
+  const router = useRouter();
+  const [token, setToken] = useState<string | null>(null);
+  const [email, setEmail] = useState("");
+  const [password, setPassword] = useState("");
+  const [confirm, setConfirm] = useState("");
+  const [requested, setRequested] = useState(false);
+
+  useEffect(() => {
+    const fromLink = searchParams.get("token");
+    if (fromLink) {
+      setToken(fromLink);
+      window.history.replaceState(null, "", window.location.pathname); // Keep the token out of history
+    }
+  }, [searchParams]);
+
+  const handleRequest = async (e: React.FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    try {
+      await api.post("/password/forgot", { email });
+      setRequested(true);
+    } catch (err: unknown) {
+      toast.error(errorMessage(err));
+    }
+  };
+
+  const handleReset = async (e: React.FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    if (password !== confirm) {
+      toast.error("The passwords don't match.");
+      return;
+    }
+    try {
+      await api.post("/password/reset", { token, password });
+      toast.success("Password changed. Log in with your new password.");
+      router.push("/auth/login");
+    } catch (err: unknown) {
+      toast.error(errorMessage(err));
+    }
+  };
+
+  if (!token) {
+    return (
+      <div className="flex flex-col items-center justify-center min-h-screen bg-gray-900 text-white">
+        <h1 className="text-3xl font-bold">Reset Password</h1>
+        {requested ? (
+          <p className="mt-6">
+            If the account exists, a reset link is on its way.
+          </p>
+        ) : (
+          <form onSubmit={handleRequest} className="flex flex-col gap-4 mt-6">
+            <input
+              type="email"
+              placeholder="Email"
+              className="p-2 bg-gray-800 rounded-md text-white"
+              value={email}
+              onChange={(e) => setEmail(e.target.value)}
+              required
+            />
+            <button
+              type="submit"
+              className="bg-blue-600 hover:bg-blue-500 text-white px-6 py-2 rounded-md"
+            >
+              Send Reset Link
+            </button>
+          </form>
+        )}
+        <Link href="/auth/login" className="mt-4 text-blue-400">
+          Back to Login
+        </Link>
+      </div>
+    );
+  }
+
+  return (
+    <div className="flex flex-col items-center justify-center min-h-screen bg-gray-900 text-white">
+      <h1 className="text-3xl font-bold">Choose a New Password</h1>
+      <form onSubmit={handleReset} className="flex flex-col gap-4 mt-6">
+        <input
+          type="password"
+          placeholder="New password"
+          className="p-2 bg-gray-800 rounded-md text-white"
+          value={password}
+          onChange={(e) => setPassword(e.target.value)}
+          required
+        />
+        <input
+          type="password"
+          placeholder="Confirm new password"
+          className="p-2 bg-gray-800 rounded-md text-white"
+          value={confirm}
+          onChange={(e) => setConfirm(e.target.value)}
+          required
+        />
+        <button
+          type="submit"
+          className="bg-blue-600 hover:bg-blue-500 text-white px-6 py-2 rounded-md"
+        >
+          Change Password
+        </button>
+      </form>
+    </div>
+  );
+}
+
+export default function ResetPasswordPage() {
+  return (
+    <Suspense>
+      <ResetPassword />
+    </Suspense>
+  );
+}
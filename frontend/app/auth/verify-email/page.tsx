@@ -0,0 +1,71 @@
+"use client";
+import { Suspense, useEffect, useRef, useState } from "react";
+import Link from "next/link";
+import { useSearchParams } from "next/navigation";
+import axios from "axios";
+import api from "@/utils/api";
+
+// ✅ Opened from the verification email: confirms the address with the token in the link
+function VerifyEmail() {
+  const searchParams = useSearchParams();
+  const [status, setStatus] = useState<"verifying" | "verified" | "failed">(
+    "verifying"
+  );
+  const [error, setError] = useState("");
+  const sent = useRef(false); // Tokens are single-use, don't send twice
+
+  useEffect(() => {
+    if (sent.current) return;
+    sent.current = true;
+
+    const token = searchParams.get("token");
+    window.history.replaceState(null, "", window.location.pathname); // Keep the token out of history
+    if (!token) {
+      setError("This verification link is incomplete.");
+      setStatus("failed");
+      return;
+    }
+
+    api
+      .post("/verify-email", { token })
+      .then(() => setStatus("verified"))
+      .catch((err: unknown) => {
+        setError(
+          (axios.isAxiosError(err) && err.response?.data?.error) ||
+            "An error occurred. Please try again."
+        );
+        setStatus("failed");
+      });
+  }, [searchParams]);
+
+  return (
+    <div className="flex flex-col items-center justify-center min-h-screen bg-gray-900 text-white">
+      <h1 className="text-3xl font-bold">Verify Email</h1>
+      <div className="flex flex-col items-center gap-4 mt-6">
+        {status === "verifying" && <p>Verifying your email address...</p>}
+        {status === "verified" && <p>Your email address is verified. 🎉</p>}
+        {status === "failed" && (
+          <p className="text-red-400">
+            {error} Log in to request a new link.
+          </p>
+        )}
+        {status !== "verifying" && (
+          <Link
+            href="/auth/login"
+            className="bg-blue-600 hover:bg-blue-500 text-white px-6 py-2 rounded-md"
+          >
+            Go to Login
+          </Link>
+        )}
+      </div>
+    </div>
+  );
+}
+
+export default function VerifyEmailPage() {
+  return (
+    <Suspense>
+      <VerifyEmail />
+    </Suspense>
+  );
+}
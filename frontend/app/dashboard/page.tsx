@@ -40,7 +40,7 @@ type Task = {
 };
 
 export default function Dashboard() {
-  const { user, logout } = useAuth();
+  const { user, loading, logout } = useAuth();
   const router = useRouter();
 
   const [tasks, setTasks] = useState<Task[]>([]);
@@ -52,8 +52,10 @@ export default function Dashboard() {
   const [editedTitle, setEditedTitle] = useState("");
   const [editedDescription, setEditedDescription] = useState("");
   const [dialogOpen, setDialogOpen] = useState(false);
+  const [linkedTaskId, setLinkedTaskId] = useState<number | null>(null);
 
   useEffect(() => {
+    if (loading) return; // Still checking for an SSO (cookie) session
     if (!user) {
       router.push("/auth/login");
       return;
@@ -63,7 +65,31 @@ export default function Dashboard() {
       setTasks(res.data);
     };
     fetchTasks();
-  }, [user, router]);
+
+    // ✅ Task links in emails carry ?owner=&task= (task IDs are only unique per owner)
+    const params = new URLSearchParams(window.location.search);
+    const taskId = Number(params.get("task"));
+    if (!taskId) return;
+    window.history.replaceState(null, "", window.location.pathname);
+
+    const ownerId = Number(params.get("owner"));
+    api.get("/me").then((res) => {
+      if (!ownerId || ownerId === res.data.id) {
+        setLinkedTaskId(taskId);
+      } else {
+        toast.info(
+          "That task belongs to another user. You'll keep getting its updates in your notifications."
+        );
+      }
+    });
+  }, [user, loading, router]);
+
+  useEffect(() => {
+    if (linkedTaskId === null) return;
+    document
+      .getElementById(`task-${linkedTaskId}`)
+      ?.scrollIntoView({ behavior: "smooth", block: "center" });
+  }, [linkedTaskId, tasks]);
 
   const toggleTask = async (task: Task) => {
     await api.put(`/tasks/${task.id}`, { completed: !task.completed });
@@ -197,7 +223,10 @@ export default function Dashboard() {
           {filteredTasks.map((task) => (
             <div
               key={task.id}
-              className="flex items-center bg-slate-800 p-4 rounded-lg"
+              id={`task-${task.id}`}
+              className={`flex items-center bg-slate-800 p-4 rounded-lg ${
+                task.id === linkedTaskId ? "ring-2 ring-blue-500" : ""
+              }`}
             >
               <Button
                 variant="ghost"
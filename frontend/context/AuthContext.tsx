@@ -12,7 +12,9 @@ interface User {
 
 interface AuthContextType {
   user: User | null;
+  loading: boolean; // True until we know whether a session exists
   login: (email: string, password: string) => Promise<void>;
+  completeMFA: (mfaToken: string) => Promise<void>;
   signup: (name: string, email: string, password: string) => Promise<void>;
   logout: () => void;
 }
@@ -21,40 +23,87 @@ const AuthContext = createContext<AuthContextType | undefined>(undefined);
 
 export const AuthProvider = ({ children }: { children: React.ReactNode }) => {
   const [user, setUser] = useState<User | null>(null);
+  const [loading, setLoading] = useState(true);
   const router = useRouter();
 
+  const storeToken = (token: string) => {
+    localStorage.setItem("token", token);
+    api.defaults.headers.common["Authorization"] = `Bearer ${token}`;
+    setUser({ token });
+  };
+
   useEffect(() => {
     const token = localStorage.getItem("token");
     if (token) {
       api.defaults.headers.common["Authorization"] = `Bearer ${token}`;
       setUser({ token });
+      setLoading(false);
+      return;
     }
+
+    // ✅ SSO logins only set cookies: trade the refresh cookie for an access token
+    api
+      .post("/token/refresh")
+      .then((res) => storeToken(res.data.token))
+      .catch(() => {}) // No cookie session either, stay logged out
+      .finally(() => setLoading(false));
   }, []);
 
+  const showLoginError = (error: unknown) => {
+    console.error("Login failed:", error);
+
+    if (axios.isAxiosError(error) && error.response) {
+      const errorMessage =
+        error.response.data?.message || "Invalid email or password";
+      toast.error(errorMessage);
+    } else {
+      toast.error("An error occurred. Please try again.");
+    }
+  };
+
+  // 🔐 Second Step for Accounts with Two-Factor Authentication
+  const verifyMFA = async (mfaToken: string) => {
+    const code = window.prompt(
+      "Enter the code from your authenticator app (or a recovery code)"
+    );
+    if (!code) return null;
+    const res = await api.post("/login/mfa", { mfa_token: mfaToken, code });
+    return res.data.token as string;
+  };
+
+  const finishLogin = (token: string) => {
+    storeToken(token);
+    toast.success("Login successful! 🎉");
+    router.push("/dashboard"); // ✅ Redirect to dashboard
+  };
+
   // 🔑 Login Function
   const login = async (email: string, password: string) => {
     try {
       console.log("Sending login request to backend...");
       const res = await api.post("/login", { email, password });
-      const token = res.data.token;
 
-      // ✅ Store token
-      localStorage.setItem("token", token);
-      api.defaults.headers.common["Authorization"] = `Bearer ${token}`;
-      setUser({ token });
+      // 🔐 Accounts with two-factor authentication need a second step
+      const token = res.data.mfa_required
+        ? await verifyMFA(res.data.mfa_token)
+        : res.data.token;
+      if (!token) return;
 
-      toast.success("Login successful! 🎉");
-      router.push("/dashboard"); // ✅ Redirect to dashboard
+      finishLogin(token);
     } catch (error: unknown) {
-      console.error("Login failed:", error);
+      showLoginError(error);
+    }
+  };
 
-      if (axios.isAxiosError(error) && error.response) {
-        const errorMessage =
-          error.response.data?.message || "Invalid email or password";
-        toast.error(errorMessage);
-      } else {
-        toast.error("An error occurred. Please try again.");
-      }
+  // 🔐 Finish an SSO Login That Stopped at the Second Factor
+  const completeMFA = async (mfaToken: string) => {
+    try {
+      const token = await verifyMFA(mfaToken);
+      if (!token) return;
+
+      finishLogin(token);
+    } catch (error: unknown) {
+      showLoginError(error);
     }
   };
 
@@ -87,6 +136,7 @@ export const AuthProvider = ({ children }: { children: React.ReactNode }) => {
 
   // 🚪 Logout Function
   const logout = () => {
+    api.post("/logout").catch(() => {}); // Revoke the session so its cookies can't log back in
     localStorage.removeItem("token");
     delete api.defaults.headers.common["Authorization"];
     setUser(null);
@@ -95,7 +145,9 @@ export const AuthProvider = ({ children }: { children: React.ReactNode }) => {
   };
 
   return (
-    <AuthContext.Provider value={{ user, login, signup, logout }}>
+    <AuthContext.Provider
+      value={{ user, loading, login, completeMFA, signup, logout }}
+    >
       {children}
     </AuthContext.Provider>
   );
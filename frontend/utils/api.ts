@@ -1,4 +1,4 @@
-import axios from "axios";
+import axios, { AxiosError, InternalAxiosRequestConfig } from "axios";
 
 // ✅ Ensure Axios is sending credentials
 const api = axios.create({
@@ -9,4 +9,35 @@ const api = axios.create({
   },
 });
 
+// ✅ Access tokens expire after 15 minutes: refresh once (using the
+// RefreshToken cookie) and replay the request that failed
+let refreshing: Promise<string> | null = null;
+
+api.interceptors.response.use(undefined, async (error: AxiosError) => {
+  const original = error.config as
+    | (InternalAxiosRequestConfig & { _retried?: boolean })
+    | undefined;
+  const expired =
+    error.response?.status === 401 &&
+    (error.response.data as { error?: string })?.error === "Token expired";
+
+  if (!original || original._retried || !expired) {
+    return Promise.reject(error);
+  }
+  original._retried = true;
+
+  refreshing ??= api
+    .post("/token/refresh")
+    .then((res) => res.data.token as string)
+    .finally(() => {
+      refreshing = null;
+    });
+
+  const token = await refreshing;
+  localStorage.setItem("token", token);
+  api.defaults.headers.common["Authorization"] = `Bearer ${token}`;
+  original.headers["Authorization"] = `Bearer ${token}`;
+  return api(original);
+});
+
 export default api;